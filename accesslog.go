@@ -0,0 +1,204 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// AccessLogOptions configures AccessLog.
+type AccessLogOptions struct {
+	// Holds the level of the records, except the ones of requests
+	// replied with 5xx, which are logged as errors.
+	Level slog.Level
+	// When above 1, only one of every SampleEvery requests is logged.
+	// Requests replied with 5xx are always logged.
+	SampleEvery int
+	// Holds the request headers to log.
+	Headers []string
+	// Holds the headers and params whose values are replaced by
+	// [REDACTED]. RedactHeaders defaults to Authorization,
+	// Proxy-Authorization and Cookie.
+	RedactHeaders []string
+	RedactParams  []string
+	// Holds the header carrying the request ID, X-Request-Id by default.
+	RequestIDHeader string
+}
+
+const redacted = "[REDACTED]"
+
+var defaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// AccessLog returns a middleware that logs every request through
+// logger, or the default logger when nil, once it's served. Records
+// have the method, host, path, matched pattern, route name, params,
+// status, bytes written, duration, remote IP and request ID.
+func AccessLog(logger *slog.Logger, opts AccessLogOptions) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	redactHeaders := opts.RedactHeaders
+	if redactHeaders == nil {
+		redactHeaders = defaultRedactHeaders
+	}
+	idHeader := opts.RequestIDHeader
+	if idHeader == "" {
+		idHeader = "X-Request-Id"
+	}
+	var count atomic.Uint64
+
+	return func(next RouteHandler) RouteHandler {
+		return RouteHandlerFunc(func(w ResponseWriter, r *Request) {
+			start := time.Now()
+			lw := &logWriter{ResponseWriter: w}
+
+			next.ServeHTTP(lw, r)
+
+			status := lw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := opts.Level
+			if status >= 500 {
+				level = slog.LevelError
+			} else if opts.SampleEvery > 1 && (count.Add(1)-1)%uint64(opts.SampleEvery) != 0 {
+				return
+			}
+			if !logger.Enabled(r.Context(), level) {
+				return
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("host", r.Host),
+				slog.String("path", r.URL.Path),
+			}
+			if rt := r.Route(); rt != nil {
+				attrs = append(attrs, slog.String("pattern", rt.Pattern))
+				if rt.Name != "" {
+					attrs = append(attrs, slog.String("route", rt.Name))
+				}
+			}
+			if params := r.Params(); len(params) > 0 {
+				attrs = append(attrs, paramsAttr(params, opts.RedactParams))
+			}
+			attrs = append(attrs,
+				slog.Int("status", status),
+				slog.Int64("bytes", lw.size),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote_ip", remoteIP(r.RemoteAddr)),
+			)
+			if id := r.Header.Get(idHeader); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			if len(opts.Headers) > 0 {
+				attrs = append(attrs, headersAttr(r.Header, opts.Headers, redactHeaders))
+			}
+
+			logger.LogAttrs(context.WithoutCancel(r.Context()), level, "request", attrs...)
+		})
+	}
+}
+
+// Groups the params, ordered by name, redacting the given ones.
+func paramsAttr(params Params, redact []string) slog.Attr {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]any, 0, len(names))
+	for _, name := range names {
+		v := params[name]
+		if slices.Contains(redact, name) {
+			v = redacted
+		}
+		attrs = append(attrs, slog.String(name, v))
+	}
+	return slog.Group("params", attrs...)
+}
+
+// Groups the given headers the request has, redacting the given ones.
+func headersAttr(h http.Header, names, redact []string) slog.Attr {
+	var attrs []any
+	for _, name := range names {
+		v, ok := h[http.CanonicalHeaderKey(name)]
+		if !ok {
+			continue
+		}
+		value := strings.Join(v, ", ")
+		if slices.ContainsFunc(redact, func(r string) bool { return strings.EqualFold(r, name) }) {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(http.CanonicalHeaderKey(name), value))
+	}
+	return slog.Group("headers", attrs...)
+}
+
+// Returns the IP of the remote address, or the address itself when
+// it has no port.
+func remoteIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// logWriter records the status and the size of the reply, keeping
+// the Flusher and Hijacker of the ResponseWriter it wraps.
+type logWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (lw *logWriter) WriteHeader(code int) {
+	if lw.status == 0 {
+		lw.status = code
+	}
+	lw.ResponseWriter.WriteHeader(code)
+}
+
+func (lw *logWriter) Write(b []byte) (int, error) {
+	if lw.status == 0 {
+		lw.status = http.StatusOK
+	}
+	n, err := lw.ResponseWriter.Write(b)
+	lw.size += int64(n)
+	return n, err
+}
+
+func (lw *logWriter) Flush() {
+	if lw.status == 0 {
+		lw.status = http.StatusOK
+	}
+	if f, ok := lw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack takes over the connection, if the wrapped ResponseWriter
+// supports it, otherwise it fails with http.ErrNotSupported. Hijacked
+// requests are logged with HTTP 101, unless a status was written.
+func (lw *logWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := lw.ResponseWriter.(http.Hijacker); ok {
+		if lw.status == 0 {
+			lw.status = http.StatusSwitchingProtocols
+		}
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap gives the wrapped ResponseWriter to http.ResponseController.
+func (lw *logWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
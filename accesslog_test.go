@@ -0,0 +1,219 @@
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Returns the records logged into buf, as JSON objects.
+func logRecords(t testing.TB, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var rec map[string]any
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+func TestAccessLog(t *testing.T) {
+	newRouter := func(buf *bytes.Buffer, opts AccessLogOptions) *Router {
+		router := NewRouter()
+		router.Wrap(AccessLog(slog.New(slog.NewJSONHandler(buf, nil)), opts))
+		router.GetFunc("/users/{id}/keys/{key}", func(w ResponseWriter, r *Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("hello"))
+		}, Name("getKey"))
+		router.GetFunc("/fail", func(w ResponseWriter, r *Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		return router
+	}
+
+	t.Run("logs the request and reply", func(t *testing.T) {
+		var buf bytes.Buffer
+		router := newRouter(&buf, AccessLogOptions{})
+
+		request := httptest.NewRequest(http.MethodGet, "/users/7/keys/abc", nil)
+		request.Header.Set("X-Request-Id", "req-1")
+		request.RemoteAddr = "10.0.0.1:5000"
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		records := logRecords(t, &buf)
+		if len(records) != 1 {
+			t.Fatalf("got %d records, but want 1", len(records))
+		}
+		rec := records[0]
+
+		want := map[string]any{
+			"level":      "INFO",
+			"msg":        "request",
+			"method":     "GET",
+			"host":       "example.com",
+			"path":       "/users/7/keys/abc",
+			"pattern":    "/users/{id}/keys/{key}",
+			"route":      "getKey",
+			"status":     float64(http.StatusCreated),
+			"bytes":      float64(5),
+			"remote_ip":  "10.0.0.1",
+			"request_id": "req-1",
+		}
+		for k, v := range want {
+			if rec[k] != v {
+				t.Errorf("got %s %v, but want %v", k, rec[k], v)
+			}
+		}
+		if params, _ := rec["params"].(map[string]any); params["id"] != "7" || params["key"] != "abc" {
+			t.Errorf("got params %v, but want id and key", rec["params"])
+		}
+		if _, ok := rec["duration"]; !ok {
+			t.Error("expected the duration logged")
+		}
+	})
+
+	t.Run("redacts headers and params", func(t *testing.T) {
+		var buf bytes.Buffer
+		router := newRouter(&buf, AccessLogOptions{
+			Headers:      []string{"Authorization", "user-agent"},
+			RedactParams: []string{"key"},
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/users/7/keys/abc", nil)
+		request.Header.Set("Authorization", "Bearer secret")
+		request.Header.Set("User-Agent", "test")
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		rec := logRecords(t, &buf)[0]
+		headers, _ := rec["headers"].(map[string]any)
+		if headers["Authorization"] != "[REDACTED]" || headers["User-Agent"] != "test" {
+			t.Errorf("got headers %v, but want Authorization redacted", headers)
+		}
+		params, _ := rec["params"].(map[string]any)
+		if params["key"] != "[REDACTED]" || params["id"] != "7" {
+			t.Errorf("got params %v, but want key redacted", params)
+		}
+	})
+
+	t.Run("samples requests but server errors", func(t *testing.T) {
+		var buf bytes.Buffer
+		router := newRouter(&buf, AccessLogOptions{SampleEvery: 3})
+
+		for i := 0; i < 6; i++ {
+			request := httptest.NewRequest(http.MethodGet, "/users/7/keys/abc", nil)
+			router.ServeHTTP(httptest.NewRecorder(), request)
+		}
+		request := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		records := logRecords(t, &buf)
+		if len(records) != 3 {
+			t.Fatalf("got %d records, but want 3", len(records))
+		}
+		if last := records[2]; last["level"] != "ERROR" || last["status"] != float64(http.StatusInternalServerError) {
+			t.Errorf("got %v, but want an error record", last)
+		}
+	})
+
+	t.Run("logs unmatched requests", func(t *testing.T) {
+		var buf bytes.Buffer
+		router := newRouter(&buf, AccessLogOptions{})
+
+		request := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		rec := logRecords(t, &buf)[0]
+		if rec["status"] != float64(http.StatusNotFound) {
+			t.Errorf("got status %v, but want 404", rec["status"])
+		}
+		if _, ok := rec["pattern"]; ok {
+			t.Errorf("got pattern %v, but want none", rec["pattern"])
+		}
+	})
+
+	t.Run("logs the timeout reply", func(t *testing.T) {
+		var buf bytes.Buffer
+		unblock := make(chan struct{})
+		defer close(unblock)
+
+		router := NewRouter()
+		router.Timeout = 10 * time.Millisecond
+		router.TimeoutBody = "timed out"
+		router.Wrap(AccessLog(slog.New(slog.NewJSONHandler(&buf, nil)), AccessLogOptions{}))
+		router.GetFunc("/slow", func(w ResponseWriter, r *Request) {
+			<-unblock
+			w.WriteHeader(http.StatusCreated)
+		})
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+		assertStatus(t, response, http.StatusServiceUnavailable)
+
+		// Logged while the handler is still blocked.
+		records := logRecords(t, &buf)
+		if len(records) != 1 {
+			t.Fatalf("got %d records, but want 1", len(records))
+		}
+		if got := records[0]["status"]; got != float64(http.StatusServiceUnavailable) {
+			t.Errorf("got status %v, but want 503", got)
+		}
+		if got := records[0]["bytes"]; got != float64(len("timed out")) {
+			t.Errorf("got bytes %v, but want %d", got, len("timed out"))
+		}
+	})
+
+	t.Run("keeps the flusher and hijacker", func(t *testing.T) {
+		var buf bytes.Buffer
+		var flushed, hijacked bool
+
+		router := NewRouter()
+		router.Wrap(AccessLog(slog.New(slog.NewJSONHandler(&buf, nil)), AccessLogOptions{}))
+		router.GetFunc("/stream", func(w ResponseWriter, r *Request) {
+			w.(http.Flusher).Flush()
+			flushed = true
+		})
+		router.GetFunc("/ws", func(w ResponseWriter, r *Request) {
+			_, _, err := w.(http.Hijacker).Hijack()
+			hijacked = err == nil
+		})
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/stream", nil))
+		if !flushed || !response.Flushed {
+			t.Error("expected the response flushed")
+		}
+
+		hr := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+		router.ServeHTTP(hr, httptest.NewRequest(http.MethodGet, "/ws", nil))
+		if !hijacked || !hr.hijacked {
+			t.Error("expected the connection hijacked")
+		}
+
+		records := logRecords(t, &buf)
+		if got := records[1]["status"]; got != float64(http.StatusSwitchingProtocols) {
+			t.Errorf("got status %v, but want 101", got)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package router
+
+import "net/http"
+
+// FromHTTP adapts a standard handler, like http.FileServer, into a
+// RouteHandler. The handler gets the embedded *http.Request, whose
+// context carries the route and params, see ParamsFromContext.
+func FromHTTP(h http.Handler) RouteHandler {
+	if h == nil {
+		panic("router: nil handler")
+	}
+	return RouteHandlerFunc(func(w ResponseWriter, r *Request) {
+		hr := r.Request
+		if routeContextOf(hr.Context()) == nil {
+			hr = hr.WithContext(r.newContext())
+		}
+		h.ServeHTTP(w, hr)
+	})
+}
+
+// ToHTTP adapts a RouteHandler into a standard handler. The Request
+// given to rh keeps the route and params carried by the request
+// context, if any, so they survive standard middlewares.
+func ToHTTP(rh RouteHandler) http.Handler {
+	if rh == nil {
+		panic("router: nil handler")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &Request{Request: r}
+		if c := routeContextOf(r.Context()); c != nil {
+			req.params = c.params
+			req.route = c.route
+			req.body = c.body
+			req.version = c.version
+			req.problems = c.problems
+		} else {
+			req.withContext()
+		}
+		rh.ServeHTTP(w, req)
+	})
+}
+
+// WrapMiddleware adapts a standard middleware, of the form
+// func(http.Handler) http.Handler, into a Middleware, keeping the
+// route and params through it.
+func WrapMiddleware(mw func(http.Handler) http.Handler) Middleware {
+	if mw == nil {
+		panic("router: nil middleware")
+	}
+	return func(next RouteHandler) RouteHandler {
+		return FromHTTP(mw(ToHTTP(next)))
+	}
+}
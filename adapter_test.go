@@ -0,0 +1,90 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdapters(t *testing.T) {
+
+	t.Run("serves standard handlers", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello"), 0o644)
+
+		router := NewRouter()
+		router.Get("/static/{file}", FromHTTP(http.StripPrefix("/static/", http.FileServer(http.Dir(dir)))))
+
+		request := httptest.NewRequest(http.MethodGet, "/static/hello.txt", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+		assertBody(t, response, "hello")
+	})
+
+	t.Run("gives params to standard handlers", func(t *testing.T) {
+		var got Params
+
+		router := NewRouter()
+		router.Get("/users/{id}", FromHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = ParamsFromContext(r.Context())
+		})))
+
+		request := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		assertParams(t, got, Params{"id": "7"})
+	})
+
+	t.Run("keeps params through standard middlewares", func(t *testing.T) {
+		type key struct{}
+		var gotParams Params
+		var gotValue any
+
+		router := NewRouter()
+		router.Wrap(WrapMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Wrapped", "yes")
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), key{}, "value")))
+			})
+		}))
+		router.GetFunc("/users/{id}", func(w ResponseWriter, r *Request) {
+			gotParams = r.Params()
+			gotValue = r.Context().Value(key{})
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		assertParams(t, gotParams, Params{"id": "7"})
+		if gotValue != "value" {
+			t.Errorf(`got %v, but want "value" from the middleware context`, gotValue)
+		}
+		if response.Header().Get("X-Wrapped") != "yes" {
+			t.Error("expected the middleware to run")
+		}
+	})
+
+	t.Run("serves route handlers as standard handlers", func(t *testing.T) {
+		var got *Request
+
+		h := ToHTTP(RouteHandlerFunc(func(w ResponseWriter, r *Request) {
+			got = r
+			w.WriteHeader(http.StatusAccepted)
+		}))
+
+		request := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+		response := httptest.NewRecorder()
+		h.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusAccepted)
+		if got == nil || len(got.Params()) != 0 || got.Route() != nil {
+			t.Errorf("got %+v, but want a request without params and route", got)
+		}
+	})
+}
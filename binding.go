@@ -0,0 +1,325 @@
+package router
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The struct tags recognized by Bind, in the order they are looked up.
+var bindSources = []string{"path", "query", "header", "cookie", "form"}
+
+// Holds the max memory used to parse multipart forms while binding.
+const defaultMaxMemory = 32 << 20
+
+var ErrUnsupportedField = errors.New("router: field type is not supported")
+
+// BindError records a failure on binding a request value into a struct field.
+type BindError struct {
+	Field  string // the field path, like Filter.Page
+	Source string // the tag that named the value, like query
+	Name   string // the value name, like page
+	Value  string // the raw value
+	Err    error  // the reason of the failure
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("router: cannot bind %s %q into %s, %v", e.Source, e.Name, e.Field, e.Err)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// BindErrors holds every failure of a Bind call.
+type BindErrors []*BindError
+
+func (e BindErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e BindErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// StatusCode returns HTTP 400.
+func (e BindErrors) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// FormError records a request form that cannot be parsed.
+type FormError struct {
+	Err error
+}
+
+func (e *FormError) Error() string {
+	return fmt.Sprintf("router: cannot parse form, %v", e.Err)
+}
+
+func (e *FormError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns HTTP 400.
+func (e *FormError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// Bind fills the struct pointed by dst with values from the request,
+// that are named by the fields tags:
+//
+//	path:"id"        from Params
+//	query:"page"     from the URL query
+//	header:"X-Token" from the request headers
+//	cookie:"session" from the request cookies
+//	form:"name"      from the parsed form
+//
+// Values are converted to the field type, which can be a string, bool, any
+// integer or float, time.Time, an encoding.TextUnmarshaler, a pointer to them
+// or a slice of them, filled with every value with the same name. The time
+// layout can be given by a layout tag, it defaults to RFC 3339. Untagged
+// struct fields are bound recursively. Fields without values are untouched.
+//
+// Like ParseBodyInto, dst must be an initialized pointer. The failures are
+// returned together as BindErrors, otherwise the struct is checked by Validate.
+func (r *Request) Bind(dst any) error {
+	v := getPtrValue(dst)
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("router: cannot bind into %T, it must point to a struct", dst)
+	}
+
+	if needsForm(v.Type()) {
+		r.rewindBody()
+		if err := parseForm(r.Request); err != nil {
+			return err
+		}
+	}
+
+	var errs BindErrors
+	bindStruct(v, "", r.bindValues, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return Validate(dst)
+}
+
+// Parses the form of r, failing with *BodyTooLargeError when its body
+// is bigger than allowed, otherwise with *FormError.
+func parseForm(r *http.Request) error {
+	if r.Form != nil {
+		return nil
+	}
+	var err error
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt == "multipart/form-data" {
+		err = r.ParseMultipartForm(defaultMaxMemory)
+	} else {
+		err = r.ParseForm()
+	}
+	if err == nil {
+		return nil
+	}
+	if err = bodyError(err); errorStatus(err) == http.StatusRequestEntityTooLarge {
+		return err
+	}
+	return &FormError{Err: err}
+}
+
+func (r *Request) bindValues(source, name string) []string {
+	switch source {
+	case "path":
+		if v, ok := r.Param(name); ok {
+			return []string{v}
+		}
+	case "query":
+		return r.URL.Query()[name]
+	case "header":
+		return r.Header.Values(name)
+	case "cookie":
+		if c, err := r.Cookie(name); err == nil {
+			return []string{c.Value}
+		}
+	case "form":
+		return r.Form[name]
+	}
+	return nil
+}
+
+func needsForm(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := f.Tag.Lookup("form"); ok {
+			return true
+		}
+		if isNestedStruct(f) && needsForm(f.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNestedStruct(f reflect.StructField) bool {
+	if f.Type.Kind() != reflect.Struct || f.Type == timeType {
+		return false
+	}
+	if reflect.PointerTo(f.Type).Implements(textUnmarshalerType) {
+		return false
+	}
+	for _, src := range bindSources {
+		if _, ok := f.Tag.Lookup(src); ok {
+			return false
+		}
+	}
+	return true
+}
+
+func bindStruct(v reflect.Value, path string, values func(source, name string) []string, errs *BindErrors) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		field := f.Name
+		if path != "" {
+			field = path + "." + f.Name
+		}
+
+		if isNestedStruct(f) {
+			bindStruct(v.Field(i), field, values, errs)
+			continue
+		}
+
+		for _, src := range bindSources {
+			name, ok := f.Tag.Lookup(src)
+			if !ok || name == "-" {
+				continue
+			}
+
+			vals := values(src, name)
+			if len(vals) == 0 {
+				continue
+			}
+
+			if err := setValues(v.Field(i), vals, f.Tag.Get("layout")); err != nil {
+				*errs = append(*errs, &BindError{
+					Field:  field,
+					Source: src,
+					Name:   name,
+					Value:  strings.Join(vals, ","),
+					Err:    err,
+				})
+			}
+			break
+		}
+	}
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// setValues sets v from the given values, only slices take them all,
+// other types take the first one.
+func setValues(v reflect.Value, values []string, layout string) error {
+	t := v.Type()
+
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && !reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		s := reflect.MakeSlice(t, len(values), len(values))
+		for i, value := range values {
+			if err := setValue(s.Index(i), value, layout); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	return setValue(v, values[0], layout)
+}
+
+// setValue converts the given text into the type of v and sets it.
+func setValue(v reflect.Value, s string, layout string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), s, layout); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+
+	if v.Type() == timeType && layout != "" {
+		tm, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(tm))
+		return nil
+	}
+
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return numError(err)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return numError(err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return numError(err)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return numError(err)
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return ErrUnsupportedField
+		}
+		v.SetBytes([]byte(s))
+	default:
+		return ErrUnsupportedField
+	}
+	return nil
+}
+
+// numError unwraps the strconv error, which repeats the value.
+func numError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindFilter struct {
+	Page  int      `query:"page"`
+	Sizes []uint16 `query:"size"`
+	Sort  *string  `query:"sort"`
+	Tags  []string `query:"tag"`
+}
+
+type bindTarget struct {
+	ID      UUID      `path:"id"`
+	Token   string    `header:"X-Token"`
+	Session string    `cookie:"session"`
+	Name    string    `form:"name"`
+	Since   time.Time `query:"since" layout:"2006-01-02"`
+	Until   time.Time `query:"until"`
+	Filter  bindFilter
+	ignored string `query:"ignored"`
+}
+
+func TestBind(t *testing.T) {
+
+	t.Run("binds values from every source", func(t *testing.T) {
+		query := "page=2&size=10&size=20&sort=name&tag=a&tag=b&since=2024-03-01&until=2024-03-02T10:00:00Z&ignored=x"
+		req, _ := http.NewRequest(http.MethodPost, newDummyURI("/items/d033fdc6-dbd2-427c-b18c-a41aa6449d75?"+query), strings.NewReader(url.Values{"name": {"rex"}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Token", "secret")
+		req.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+
+		request := &Request{
+			params:  Params{"id": "d033fdc6-dbd2-427c-b18c-a41aa6449d75"},
+			Request: req,
+		}
+
+		var got bindTarget
+		err := request.Bind(&got)
+
+		assertNoError(t, err)
+
+		sort := "name"
+		want := bindTarget{
+			ID:      UUID{0xd0, 0x33, 0xfd, 0xc6, 0xdb, 0xd2, 0x42, 0x7c, 0xb1, 0x8c, 0xa4, 0x1a, 0xa6, 0x44, 0x9d, 0x75},
+			Token:   "secret",
+			Session: "s1",
+			Name:    "rex",
+			Since:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Until:   time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC),
+			Filter: bindFilter{
+				Page:  2,
+				Sizes: []uint16{10, 20},
+				Sort:  &sort,
+				Tags:  []string{"a", "b"},
+			},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, but want %+v", got, want)
+		}
+	})
+
+	t.Run("returns field errors", func(t *testing.T) {
+		request := newRequest(http.MethodGet, newDummyURI("/items?page=a&size=70000&since=yesterday"), "")
+
+		var got bindTarget
+		err := request.Bind(&got)
+
+		var errs BindErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("got error %v, but want BindErrors", err)
+		}
+
+		var fields []string
+		for _, e := range errs {
+			fields = append(fields, e.Source+" "+e.Name+" "+e.Field)
+		}
+		want := []string{"query since Since", "query page Filter.Page", "query size Filter.Sizes"}
+		if !reflect.DeepEqual(fields, want) {
+			t.Errorf("got failed fields %q, but want %q", fields, want)
+		}
+		if errs.StatusCode() != http.StatusBadRequest {
+			t.Errorf("got status %d, but want %d", errs.StatusCode(), http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns error for malformed forms", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, newDummyURI("/items"), strings.NewReader("name=%zz"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request := &Request{Request: req}
+
+		var got bindTarget
+		err := request.Bind(&got)
+
+		var formErr *FormError
+		if !errors.As(err, &formErr) {
+			t.Fatalf("got error %v, but want *FormError", err)
+		}
+		if errorStatus(err) != http.StatusBadRequest {
+			t.Errorf("got status %d, but want %d", errorStatus(err), http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns error for non struct", func(t *testing.T) {
+		request := newRequest(http.MethodGet, newDummyURI("/items"), "")
+
+		var got int
+		if err := request.Bind(&got); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
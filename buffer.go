@@ -0,0 +1,129 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+// Holds the memory used to buffer bodies read by the router itself.
+const defaultBodyBufferSize = 1 << 20
+
+var ErrBodyNotBuffered = errors.New("router: request body is not buffered")
+
+// bodyBuffer holds a whole request body, in memory or
+// in a temporary file, to be read any number of times.
+type bodyBuffer struct {
+	mem  []byte
+	file *os.File
+	size int64
+}
+
+// newBodyBuffer reads r into memory, until it gets bigger than
+// memLimit bytes, then moves everything into a temporary file.
+func newBodyBuffer(r io.Reader, memLimit int64) (*bodyBuffer, error) {
+	var mem bytes.Buffer
+	n, err := io.Copy(&mem, io.LimitReader(r, memLimit+1))
+	if err != nil {
+		return nil, err
+	}
+	if n <= memLimit {
+		return &bodyBuffer{mem: mem.Bytes(), size: n}, nil
+	}
+
+	f, err := os.CreateTemp("", "router-body-*")
+	if err != nil {
+		return nil, err
+	}
+	b := &bodyBuffer{file: f}
+
+	b.size, err = io.Copy(f, io.MultiReader(&mem, r))
+	if err != nil {
+		b.close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *bodyBuffer) reader() io.ReadCloser {
+	if b.file != nil {
+		return io.NopCloser(io.NewSectionReader(b.file, 0, b.size))
+	}
+	return io.NopCloser(bytes.NewReader(b.mem))
+}
+
+func (b *bodyBuffer) close() error {
+	if b.file == nil {
+		return nil
+	}
+	err := b.file.Close()
+	if rerr := os.Remove(b.file.Name()); err == nil {
+		err = rerr
+	}
+	b.file = nil
+	return err
+}
+
+// BufferBody reads the whole request body, holding it in memory up to
+// memLimit bytes, and in a temporary file when bigger, so the body can
+// be read more than once. Then r.Body and r.GetBody give the body from
+// its beginning. Calling it again just rewinds the body.
+//
+// ParseBodyInto, Bind and other Request methods rewind buffered bodies
+// before reading them, whoever else reads r.Body directly should call
+// RewindBody after. The router removes the temporary file once the
+// handler returns.
+func (r *Request) BufferBody(memLimit int64) error {
+	if r.body != nil {
+		return r.RewindBody()
+	}
+	if r.Body == nil {
+		return ErrNilBody
+	}
+
+	b, err := newBodyBuffer(r.Body, memLimit)
+	if err != nil {
+		return bodyError(err)
+	}
+	r.Body.Close()
+
+	r.body = b
+	r.GetBody = func() (io.ReadCloser, error) {
+		return b.reader(), nil
+	}
+	return r.RewindBody()
+}
+
+// RewindBody makes r.Body give the buffered body from its beginning.
+// It fails with ErrBodyNotBuffered when BufferBody wasn't called.
+func (r *Request) RewindBody() error {
+	if r.body == nil {
+		return ErrBodyNotBuffered
+	}
+	r.Body = r.body.reader()
+	return nil
+}
+
+// rewindBody rewinds the body when it's buffered.
+func (r *Request) rewindBody() {
+	if r.body != nil {
+		r.RewindBody()
+	}
+}
+
+// closeBody releases the resources held by the buffered body.
+func (r *Request) closeBody() {
+	if r.body != nil {
+		r.body.close()
+	}
+}
+
+// BufferBody makes the router buffer the route request bodies, like
+// Request.BufferBody does, overriding the router BodyBufferSize.
+// A negative memLimit disables the buffering.
+func BufferBody(memLimit int64) RouteOption {
+	return func(rt *Route) {
+		rt.BodyBufferSize = memLimit
+	}
+}
@@ -0,0 +1,116 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestBufferBody(t *testing.T) {
+
+	t.Run("reads body more than once", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/words"), "router")
+
+		assertNoError(t, request.BufferBody(64))
+
+		raw, _ := io.ReadAll(request.Body)
+		if string(raw) != "router" {
+			t.Errorf(`got %q, but want "router"`, raw)
+		}
+
+		var got string
+		assertNoError(t, request.ParseBodyInto(&got))
+		if got != "router" {
+			t.Errorf(`got %q, but want "router" again`, got)
+		}
+
+		assertNoError(t, request.RewindBody())
+		raw, _ = io.ReadAll(request.Body)
+		if string(raw) != "router" {
+			t.Errorf(`got %q, but want "router" after rewind`, raw)
+		}
+
+		body, _ := request.GetBody()
+		raw, _ = io.ReadAll(body)
+		if string(raw) != "router" {
+			t.Errorf(`got %q, but want "router" from GetBody`, raw)
+		}
+	})
+
+	t.Run("spills big bodies to a temporary file", func(t *testing.T) {
+		body := strings.Repeat("a", 100)
+		request := newRequest(http.MethodPost, newDummyURI("/words"), body)
+
+		assertNoError(t, request.BufferBody(10))
+
+		if request.body.file == nil {
+			t.Fatal("expected body in a temporary file")
+		}
+		name := request.body.file.Name()
+
+		var got string
+		assertNoError(t, request.ParseBodyInto(&got))
+		if got != body {
+			t.Errorf("got %d bytes, but want %d", len(got), len(body))
+		}
+
+		request.closeBody()
+
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("expected temporary file to be removed, %v", err)
+		}
+	})
+
+	t.Run("fails to rewind not buffered body", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/words"), "router")
+
+		if err := request.RewindBody(); err != ErrBodyNotBuffered {
+			t.Errorf("got error %v, but want %v", err, ErrBodyNotBuffered)
+		}
+	})
+}
+
+func TestRouterBodyBuffering(t *testing.T) {
+
+	var seen, got string
+
+	router := NewRouter()
+	router.BodyBufferSize = 4
+	router.Wrap(func(next RouteHandler) RouteHandler {
+		return RouteHandlerFunc(func(w ResponseWriter, r *Request) {
+			raw, _ := io.ReadAll(r.Body)
+			seen = string(raw)
+			next.ServeHTTP(w, r)
+		})
+	})
+	router.PostFunc("/words", func(w ResponseWriter, r *Request) {
+		r.ParseBodyInto(&got)
+	})
+	router.PostFunc("/raw", func(w ResponseWriter, r *Request) {
+		raw, _ := io.ReadAll(r.Body)
+		got = string(raw)
+	}, BufferBody(-1))
+
+	t.Run("buffers bodies for middlewares and handlers", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, newDummyURI("/words"), strings.NewReader("science"))
+
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		if seen != "science" || got != "science" {
+			t.Errorf("got %q and %q, but want both \"science\"", seen, got)
+		}
+	})
+
+	t.Run("disables buffering per route", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, newDummyURI("/raw"), strings.NewReader("science"))
+
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		if seen != "science" || got != "" {
+			t.Errorf("got %q and %q, but want \"science\" and nothing", seen, got)
+		}
+	})
+}
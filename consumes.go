@@ -0,0 +1,70 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Consumes makes the route serve only requests whose body is of one
+// of the given media types, like application/json, or image/* for any
+// image type. Requests without Content-Type aren't restricted.
+//
+// Routes with Consumes or Produces can share their pattern and method,
+// being tried in the order they were registered. When none of them fits
+// the request, and there's no route without them, the router replies
+// HTTP 415 or 406.
+func Consumes(mediaTypes ...string) RouteOption {
+	return func(rt *Route) {
+		rt.Consumes = append(rt.Consumes, mediaTypes...)
+	}
+}
+
+// Produces makes the route serve only requests accepting one of the
+// given media types, through the Accept header. See Consumes. Among
+// the routes that fit, the one producing the media type of highest
+// quality in the Accept header is chosen.
+func Produces(mediaTypes ...string) RouteOption {
+	return func(rt *Route) {
+		rt.Produces = append(rt.Produces, mediaTypes...)
+	}
+}
+
+// Reports whether the route consumes the request body.
+func (rt *Route) consumes(r *http.Request) bool {
+	ct := r.Header.Get("Content-Type")
+	if len(rt.Consumes) == 0 || ct == "" {
+		return true
+	}
+
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	for _, c := range rt.Consumes {
+		if mediaTypeMatches(c, mt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the quality value of the media type the route produces that
+// the request accepts best, zero means it produces none of them.
+func (rt *Route) produces(r *http.Request) float64 {
+	if len(rt.Produces) == 0 {
+		return 1
+	}
+	_, q := negotiate(r.Header.Get("Accept"), rt.Produces)
+	return q
+}
+
+// Reports whether the media type fits the pattern, which can be
+// like type/* or */*, to allow any subtype or type.
+func mediaTypeMatches(pattern, mt string) bool {
+	pattern = strings.ToLower(pattern)
+	if pattern == mt || pattern == "*/*" {
+		return true
+	}
+	return strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mt, pattern[:len(pattern)-1])
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConsumesProduces(t *testing.T) {
+	router := NewRouter()
+	router.Post("/pets", namedHandler("json"), Consumes("application/json"))
+	router.Post("/pets", namedHandler("form"), Consumes("application/x-www-form-urlencoded", "multipart/*"))
+	router.Get("/pets", namedHandler("csv"), Produces("text/csv"))
+	router.Get("/pets", namedHandler("html"), Produces("text/html"))
+	router.Put("/pets", namedHandler("any"), Consumes("application/json"))
+	router.Put("/pets", namedHandler("fallback"))
+
+	cases := []struct {
+		name        string
+		method      string
+		contentType string
+		accept      string
+		status      int
+		body        string
+	}{
+		{"json body", http.MethodPost, "application/json; charset=utf-8", "", http.StatusOK, "json"},
+		{"form body", http.MethodPost, "application/x-www-form-urlencoded", "", http.StatusOK, "form"},
+		{"multipart body", http.MethodPost, "multipart/form-data; boundary=x", "", http.StatusOK, "form"},
+		{"unsupported body", http.MethodPost, "text/csv", "", http.StatusUnsupportedMediaType, ""},
+		{"body without type", http.MethodPost, "", "", http.StatusOK, "json"},
+		{"accepted csv", http.MethodGet, "", "text/csv", http.StatusOK, "csv"},
+		{"accepted html", http.MethodGet, "", "text/csv;q=0.5, text/html", http.StatusOK, "html"},
+		{"accepted wildcard", http.MethodGet, "", "text/*", http.StatusOK, "csv"},
+		{"not acceptable", http.MethodGet, "", "application/json", http.StatusNotAcceptable, ""},
+		{"fallback route", http.MethodPut, "text/csv", "", http.StatusOK, "fallback"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request := httptest.NewRequest(c.method, "/pets", strings.NewReader("body"))
+			request.Header.Set("Content-Type", c.contentType)
+			request.Header.Set("Accept", c.accept)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assertStatus(t, response, c.status)
+			assertBody(t, response, c.body)
+		})
+	}
+
+	t.Run("documents the media types", func(t *testing.T) {
+		router := NewRouter()
+		router.PostFunc("/pets", dummyHandlerFunc, Consumes("application/xml"), Produces("application/xml"),
+			Accepts(pet{}), Returns(http.StatusCreated, pet{}))
+
+		op := router.OpenAPI(OpenAPIInfo{}).Paths["/pets"].Post
+		if _, ok := op.RequestBody.Content["application/xml"]; !ok || len(op.RequestBody.Content) != 1 {
+			t.Errorf("got request content %v, but want only application/xml", op.RequestBody.Content)
+		}
+		if _, ok := op.Responses["201"].Content["application/xml"]; !ok {
+			t.Errorf("got response content %v, but want application/xml", op.Responses["201"].Content)
+		}
+	})
+
+	t.Run("documents the media types of every route", func(t *testing.T) {
+		router := NewRouter()
+		router.PostFunc("/pets", dummyHandlerFunc, Consumes("application/json"), Accepts(pet{}))
+		router.PostFunc("/pets", dummyHandlerFunc, Consumes("application/x-www-form-urlencoded"),
+			Accepts(pet{}), Returns(http.StatusCreated, nil))
+
+		doc := router.OpenAPI(OpenAPIInfo{})
+		op := doc.Paths["/pets"].Post
+		var got []string
+		for mt := range op.RequestBody.Content {
+			got = append(got, mt)
+		}
+		assertSameStrings(t, got, []string{"application/json", "application/x-www-form-urlencoded"})
+		if _, ok := op.Responses["201"]; !ok || len(op.Responses) != 1 {
+			t.Errorf("got responses %v, but want only 201", op.Responses)
+		}
+
+		mw, err := ValidateRequests(router, doc)
+		assertNoError(t, err)
+		router.Wrap(mw)
+
+		request := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader("name=rex"))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+	})
+}
@@ -0,0 +1,78 @@
+package router
+
+import "context"
+
+// requestContextKey is the context key of the routeContext of requests
+// dispatched by the router.
+type requestContextKey struct{}
+
+// routeContext carries what the router found out about a request,
+// copied out of the Request, which is reused once the handler returns,
+// so it's still valid for code keeping the *http.Request. It's the
+// context itself, so carrying it costs a single allocation.
+type routeContext struct {
+	context.Context
+	route    *Route
+	params   Params
+	version  string
+	problems bool
+	body     *bodyBuffer
+}
+
+func (c *routeContext) Value(key any) any {
+	if key == (requestContextKey{}) {
+		return c
+	}
+	return c.Context.Value(key)
+}
+
+// withContext makes the context of r carry its route and params, so
+// they are found by code that only sees the *http.Request.
+func (r *Request) withContext() {
+	r.Request = r.Request.WithContext(r.newContext())
+}
+
+// newContext returns the context of r carrying its route and params.
+// The params are copied out of r, which shares the copy from then on,
+// see Request.Params.
+func (r *Request) newContext() *routeContext {
+	if len(r.pathParams) > 0 && r.params == nil {
+		r.params = r.pathParams.toParams()
+	}
+	return &routeContext{
+		Context:  r.Context(),
+		route:    r.route,
+		params:   r.params,
+		version:  r.version,
+		problems: r.problems,
+		body:     r.body,
+	}
+}
+
+func routeContextOf(ctx context.Context) *routeContext {
+	c, _ := ctx.Value(requestContextKey{}).(*routeContext)
+	return c
+}
+
+// ParamsFromContext returns the params of the request whose context is
+// ctx, as given by Request.Params, or nil when the request wasn't
+// dispatched by a Router. They stay valid after the handler returns.
+func ParamsFromContext(ctx context.Context) Params {
+	if c := routeContextOf(ctx); c != nil {
+		if c.params == nil {
+			return Params{}
+		}
+		return c.params
+	}
+	return nil
+}
+
+// RouteFromContext returns the route matched by the request whose
+// context is ctx, which holds its pattern and name, or nil when the
+// request didn't match any route or wasn't dispatched by a Router.
+func RouteFromContext(ctx context.Context) *Route {
+	if c := routeContextOf(ctx); c != nil {
+		return c.route
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+
+	t.Run("carries the route and params", func(t *testing.T) {
+		var gotRoute *Route
+		var gotParams Params
+
+		router := NewRouter()
+		router.GetFunc("/users/{id}", func(w ResponseWriter, r *Request) {
+			// Reads through the *http.Request, like std library code does.
+			std := r.Request
+			gotRoute = RouteFromContext(std.Context())
+			gotParams = ParamsFromContext(std.Context())
+		}, Name("getUser"))
+
+		request := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		if gotRoute == nil || gotRoute.Pattern != "/users/{id}" || gotRoute.Name != "getUser" {
+			t.Errorf("got route %+v, but want getUser", gotRoute)
+		}
+		assertParams(t, gotParams, Params{"id": "42"})
+	})
+
+	t.Run("is seen by middlewares", func(t *testing.T) {
+		var got *Route
+
+		router := NewRouter()
+		router.Get("/users", dummyHandler)
+		router.Wrap(func(next RouteHandler) RouteHandler {
+			return RouteHandlerFunc(func(w ResponseWriter, r *Request) {
+				got = RouteFromContext(r.Context())
+				next.ServeHTTP(w, r)
+			})
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/users", nil)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		if got == nil || got.Pattern != "/users" {
+			t.Errorf("got route %+v, but want /users", got)
+		}
+	})
+
+	t.Run("gives nothing out of the router", func(t *testing.T) {
+		if got := RouteFromContext(context.Background()); got != nil {
+			t.Errorf("got route %+v, but want nil", got)
+		}
+		if got := ParamsFromContext(context.Background()); got != nil {
+			t.Errorf("got params %v, but want nil", got)
+		}
+	})
+
+	t.Run("outlives the handler", func(t *testing.T) {
+		var kept []*http.Request
+
+		router := NewRouter()
+		router.GetFunc("/a/{id}", func(w ResponseWriter, r *Request) {
+			kept = append(kept, r.Request)
+		})
+
+		for _, url := range []string{"/a/1", "/a/2"} {
+			request := httptest.NewRequest(http.MethodGet, url, nil)
+			router.ServeHTTP(httptest.NewRecorder(), request)
+		}
+
+		for i, want := range []string{"1", "2"} {
+			ctx := kept[i].Context()
+			assertParams(t, ParamsFromContext(ctx), Params{"id": want})
+			if rt := RouteFromContext(ctx); rt == nil || rt.Pattern != "/a/{id}" {
+				t.Errorf("got route %+v, but want /a/{id}", rt)
+			}
+		}
+	})
+
+	t.Run("is read concurrently", func(t *testing.T) {
+		router := NewRouter()
+		router.GetFunc("/a/{id}", func(w ResponseWriter, r *Request) {
+			done := make(chan struct{})
+			go func() {
+				ParamsFromContext(r.Context())
+				close(done)
+			}()
+			ParamsFromContext(r.Context())
+			<-done
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				request := httptest.NewRequest(http.MethodGet, "/a/1", nil)
+				router.ServeHTTP(httptest.NewRecorder(), request)
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("gives no route to unmatched requests", func(t *testing.T) {
+		got := &Route{}
+
+		router := NewRouter()
+		router.Wrap(func(next RouteHandler) RouteHandler {
+			return RouteHandlerFunc(func(w ResponseWriter, r *Request) {
+				got = RouteFromContext(r.Context())
+			})
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		if got != nil {
+			t.Errorf("got route %+v, but want nil", got)
+		}
+	})
+}
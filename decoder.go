@@ -0,0 +1,240 @@
+package router
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Decoder parses the body of a request into v, which is
+// an initialized pointer.
+type Decoder interface {
+	Decode(r *http.Request, v any) error
+}
+
+// An adapter to allow the use of functions as decoders.
+type DecoderFunc func(r *http.Request, v any) error
+
+func (f DecoderFunc) Decode(r *http.Request, v any) error {
+	return f(r, v)
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{
+		"application/json":                  JSONDecoder{},
+		"application/xml":                   XMLDecoder{},
+		"text/xml":                          XMLDecoder{},
+		"application/x-www-form-urlencoded": FormDecoder{},
+		"multipart/form-data":               FormDecoder{},
+	}
+)
+
+// RegisterDecoder records the decoder used by ParseBodyInto for
+// requests of the given media type, like application/msgpack.
+// It replaces any decoder of the same media type.
+func RegisterDecoder(mediaType string, d Decoder) {
+	if d == nil {
+		panic("router: nil decoder")
+	}
+
+	mediaType = strings.ToLower(mediaType)
+	if _, _, err := mime.ParseMediaType(mediaType); err != nil {
+		panic("router: invalid media type " + mediaType)
+	}
+
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+
+	decoders[mediaType] = d
+}
+
+// UnsupportedMediaTypeError records a request body whose media
+// type has no registered decoder.
+type UnsupportedMediaTypeError struct {
+	MediaType string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("router: unsupported media type %q", e.MediaType)
+}
+
+// StatusCode returns HTTP 415.
+func (e *UnsupportedMediaTypeError) StatusCode() int {
+	return http.StatusUnsupportedMediaType
+}
+
+// decoderFor returns the decoder for the given Content-Type, which
+// defaults to JSON when empty. Structured syntax suffixes, like the
+// one in application/problem+json, are also recognized.
+func decoderFor(contentType string) (Decoder, error) {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, &UnsupportedMediaTypeError{contentType}
+	}
+
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	if d, ok := decoders[mt]; ok {
+		return d, nil
+	}
+
+	if i := strings.LastIndexByte(mt, '+'); i > 0 {
+		if d, ok := decoders["application/"+mt[i+1:]]; ok {
+			return d, nil
+		}
+	}
+
+	return nil, &UnsupportedMediaTypeError{mt}
+}
+
+var ErrTrailingData = errors.New("router: unexpected data after the body value")
+
+// DecodeError records a failure on decoding a request body, giving
+// what is known about where it happened.
+type DecodeError struct {
+	Type   reflect.Type // the type the body was decoded into
+	Field  string       // the path of the offending field, if known
+	Offset int64        // the byte offset where the failure happened, or where the failed value ends
+	Err    error        // the reason of the failure
+}
+
+func (e *DecodeError) Error() string {
+	msg := fmt.Sprintf("router: cannot parse request body into %v", e.Type)
+	if e.Field != "" {
+		msg += fmt.Sprintf(", field %q", e.Field)
+	}
+	return msg + fmt.Sprintf(" at offset %d, %v", e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns HTTP 400.
+func (e *DecodeError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// JSONDecoder decodes JSON bodies, failing with *DecodeError.
+//
+// The zero value is lenient, like encoding/json. To change the
+// options register a configured one:
+//
+//	router.RegisterDecoder("application/json", router.JSONDecoder{DisallowUnknownFields: true})
+type JSONDecoder struct {
+	// Causes failure when an object has a key that doesn't match
+	// any exported field of the destination struct.
+	DisallowUnknownFields bool
+	// Causes failure when anything other than white space
+	// follows the first JSON value.
+	DisallowTrailingData bool
+	// Decodes numbers into interfaces as json.Number,
+	// instead of float64.
+	UseNumber bool
+}
+
+func (d JSONDecoder) Decode(r *http.Request, v any) error {
+	dec := d.newDecoder(r.Body)
+
+	err := dec.Decode(v)
+	offset := dec.InputOffset()
+	if err == nil && d.DisallowTrailingData {
+		if _, terr := dec.Token(); terr != io.EOF {
+			err = ErrTrailingData
+		}
+	}
+	if err == nil {
+		return nil
+	}
+
+	de := &DecodeError{Type: reflect.TypeOf(v).Elem(), Offset: offset, Err: err}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		de.Offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		de.Field = typeErr.Field
+		de.Offset = typeErr.Offset
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		de.Field, _ = strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+	}
+
+	return de
+}
+
+func (d JSONDecoder) newDecoder(r io.Reader) *json.Decoder {
+	dec := json.NewDecoder(r)
+	if d.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if d.UseNumber {
+		dec.UseNumber()
+	}
+	return dec
+}
+
+// jsonDecoder returns the registered JSON decoder, or the zero one
+// when a different kind of decoder is registered for JSON.
+func jsonDecoder() JSONDecoder {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	d, _ := decoders["application/json"].(JSONDecoder)
+	return d
+}
+
+// XMLDecoder decodes XML bodies, failing with *DecodeError.
+type XMLDecoder struct{}
+
+func (XMLDecoder) Decode(r *http.Request, v any) error {
+	dec := xml.NewDecoder(r.Body)
+	if err := dec.Decode(v); err != nil {
+		return &DecodeError{Type: reflect.TypeOf(v).Elem(), Offset: dec.InputOffset(), Err: err}
+	}
+	return nil
+}
+
+// FormDecoder decodes URL encoded and multipart forms into structs,
+// by binding the form values into the fields with form tags, just
+// like Request.Bind does.
+type FormDecoder struct{}
+
+func (FormDecoder) Decode(r *http.Request, v any) error {
+	value := getPtrValue(v)
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("router: cannot parse form into %T", v)
+	}
+
+	if err := parseForm(r); err != nil {
+		return err
+	}
+
+	var errs BindErrors
+	bindStruct(value, "", func(source, name string) []string {
+		if source == "form" {
+			return r.Form[name]
+		}
+		return nil
+	}, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type decodedPerson struct {
+	Id   int    `xml:"id" form:"id"`
+	Name string `xml:"name" form:"name"`
+}
+
+func TestParseBodyIntoByContentType(t *testing.T) {
+
+	want := decodedPerson{1, "Alex"}
+
+	multipartBody := &bytes.Buffer{}
+	mw := multipart.NewWriter(multipartBody)
+	mw.WriteField("id", "1")
+	mw.WriteField("name", "Alex")
+	mw.Close()
+
+	cases := []struct {
+		contentType string
+		body        string
+	}{
+		{"", `{"Id": 1, "Name": "Alex"}`},
+		{"application/json; charset=utf-8", `{"Id": 1, "Name": "Alex"}`},
+		{"application/vnd.api+json", `{"Id": 1, "Name": "Alex"}`},
+		{"application/xml", `<person><id>1</id><name>Alex</name></person>`},
+		{"text/xml", `<person><id>1</id><name>Alex</name></person>`},
+		{"application/x-www-form-urlencoded", "id=1&name=Alex"},
+		{mw.FormDataContentType(), multipartBody.String()},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("parses %q body", c.contentType), func(t *testing.T) {
+			request := newRequest(http.MethodPost, newDummyURI("/persons"), c.body)
+			request.Header.Set("Content-Type", c.contentType)
+
+			var got decodedPerson
+			err := request.ParseBodyInto(&got)
+
+			assertNoError(t, err)
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, but want %+v", got, want)
+			}
+		})
+	}
+
+	t.Run("uses registered decoders", func(t *testing.T) {
+		RegisterDecoder("text/csv", DecoderFunc(func(r *http.Request, v any) error {
+			raw, _ := io.ReadAll(r.Body)
+			id, name, _ := strings.Cut(string(raw), ",")
+			p := v.(*decodedPerson)
+			fmt.Sscan(id, &p.Id)
+			p.Name = name
+			return nil
+		}))
+
+		request := newRequest(http.MethodPost, newDummyURI("/persons"), "1,Alex")
+		request.Header.Set("Content-Type", "text/csv")
+
+		var got decodedPerson
+		err := request.ParseBodyInto(&got)
+
+		assertNoError(t, err)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, but want %+v", got, want)
+		}
+	})
+
+	t.Run("returns error for unsupported media type", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/persons"), "1")
+		request.Header.Set("Content-Type", "application/cbor")
+
+		var got decodedPerson
+		err := request.ParseBodyInto(&got)
+
+		var mte *UnsupportedMediaTypeError
+		if !errors.As(err, &mte) {
+			t.Fatalf("got error %v, but want *UnsupportedMediaTypeError", err)
+		}
+		if mte.MediaType != "application/cbor" {
+			t.Errorf("got media type %q, but want %q", mte.MediaType, "application/cbor")
+		}
+		if mte.StatusCode() != http.StatusUnsupportedMediaType {
+			t.Errorf("got status %d, but want %d", mte.StatusCode(), http.StatusUnsupportedMediaType)
+		}
+	})
+}
+
+func TestJSONDecoder(t *testing.T) {
+
+	type address struct {
+		Zip int `json:"zip"`
+	}
+	type person struct {
+		Name    string  `json:"name"`
+		Address address `json:"address"`
+	}
+
+	cases := []struct {
+		name    string
+		decoder JSONDecoder
+		body    string
+		field   string
+		offset  int64
+		err     error
+	}{
+		{"syntax error", JSONDecoder{}, `{"name": "Alex",}`, "", 17, nil},
+		{"type error", JSONDecoder{}, `{"name": "Alex", "address": {"zip": "a"}}`, "address.zip", 39, nil},
+		{"unknown field", JSONDecoder{DisallowUnknownFields: true}, `{"name": "Alex", "age": 3}`, "age", 26, nil},
+		{"trailing data", JSONDecoder{DisallowTrailingData: true}, `{"name": "Alex"} {}`, "", 16, ErrTrailingData},
+		{"empty body", JSONDecoder{}, ``, "", 0, io.EOF},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request := newRequest(http.MethodPost, newDummyURI("/persons"), c.body)
+
+			var got person
+			err := c.decoder.Decode(request.Request, &got)
+
+			var de *DecodeError
+			if !errors.As(err, &de) {
+				t.Fatalf("got error %v, but want *DecodeError", err)
+			}
+			if de.Field != c.field {
+				t.Errorf("got field %q, but want %q", de.Field, c.field)
+			}
+			if de.Offset != c.offset {
+				t.Errorf("got offset %d, but want %d", de.Offset, c.offset)
+			}
+			if c.err != nil && !errors.Is(err, c.err) {
+				t.Errorf("got error %v, but want it to wrap %v", err, c.err)
+			}
+			if de.StatusCode() != http.StatusBadRequest {
+				t.Errorf("got status %d, but want %d", de.StatusCode(), http.StatusBadRequest)
+			}
+		})
+	}
+
+	t.Run("accepts lenient bodies by default", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/persons"), `{"name": "Alex", "age": 3} trailing`)
+
+		var got person
+		err := JSONDecoder{}.Decode(request.Request, &got)
+
+		assertNoError(t, err)
+	})
+
+	t.Run("uses numbers", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/values"), `{"n": 12345678901234567890}`)
+
+		var got map[string]any
+		err := JSONDecoder{UseNumber: true}.Decode(request.Request, &got)
+
+		assertNoError(t, err)
+
+		if got["n"] != json.Number("12345678901234567890") {
+			t.Errorf("got %#v, but want json.Number", got["n"])
+		}
+	})
+
+	t.Run("surfaces from ParseBodyInto", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/persons"), `{"name": 1}`)
+
+		var got person
+		err := request.ParseBodyInto(&got)
+
+		var de *DecodeError
+		if !errors.As(err, &de) || de.Field != "name" {
+			t.Errorf("got error %v, but want *DecodeError on name", err)
+		}
+	})
+}
@@ -1,3 +1,3 @@
 module github.com/xandalm/go-router
 
-go 1.21.4
+go 1.22
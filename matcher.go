@@ -0,0 +1,127 @@
+package router
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// MatcherFunc reports whether the request fits a route,
+// besides its pattern and method.
+type MatcherFunc func(r *http.Request) bool
+
+// MatchFunc makes the route match only requests accepted by fn.
+//
+// Routes with matchers can share their pattern and method with
+// other routes, being tried in the order they were registered,
+// before the route without matchers, if any.
+func MatchFunc(fn MatcherFunc) RouteOption {
+	if fn == nil {
+		panic("router: nil matcher")
+	}
+	return func(rt *Route) {
+		rt.matchers = append(rt.matchers, fn)
+	}
+}
+
+// Header makes the route match only requests having the header
+// key with the given value. An empty value matches any value, as
+// long as the header is present.
+func Header(key, value string) RouteOption {
+	key = http.CanonicalHeaderKey(key)
+	return MatchFunc(func(r *http.Request) bool {
+		values, ok := r.Header[key]
+		if !ok {
+			return false
+		}
+		if value == "" {
+			return true
+		}
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// HeaderRegexp makes the route match only requests having the
+// header key with a value matching the regular expression.
+func HeaderRegexp(key, pattern string) RouteOption {
+	key = http.CanonicalHeaderKey(key)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		panic("router: invalid header pattern " + pattern)
+	}
+	return MatchFunc(func(r *http.Request) bool {
+		for _, v := range r.Header[key] {
+			if re.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Query makes the route match only requests having the query
+// parameter key with the given value. An empty value matches any
+// value, as long as the parameter is present.
+func Query(key, value string) RouteOption {
+	return MatchFunc(func(r *http.Request) bool {
+		values, ok := r.URL.Query()[key]
+		if !ok {
+			return false
+		}
+		if value == "" {
+			return true
+		}
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Scheme makes the route match only requests of the given schemes,
+// like https. The scheme is taken from the request URL when absolute,
+// or else from the connection, being https when TLS is used.
+func Scheme(schemes ...string) RouteOption {
+	return MatchFunc(func(r *http.Request) bool {
+		s := requestScheme(r)
+		for _, scheme := range schemes {
+			if strings.EqualFold(s, scheme) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func requestScheme(r *http.Request) string {
+	if r.URL.Scheme != "" {
+		return r.URL.Scheme
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Reports whether the request fits every matcher of the route.
+func (rt *Route) matches(r *http.Request) bool {
+	for _, m := range rt.matchers {
+		if !m(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Reports whether the route is selected by more than
+// its pattern and method.
+func (rt *Route) conditional() bool {
+	return len(rt.matchers) > 0 || rt.Version != "" || len(rt.Consumes) > 0 || len(rt.Produces) > 0
+}
@@ -0,0 +1,142 @@
+package router
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func namedHandler(name string) RouteHandlerFunc {
+	return func(w ResponseWriter, r *Request) {
+		w.Write([]byte(name))
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	router := NewRouter()
+	router.Get("/items", namedHandler("v2"), Header("X-API-Version", "2"))
+	router.Get("/items", namedHandler("beta"), HeaderRegexp("X-API-Version", `^3\.\d+-beta$`))
+	router.Get("/items", namedHandler("search"), Query("q", ""))
+	router.Get("/items", namedHandler("sorted"), Query("sort", "name"))
+	router.Get("/items", namedHandler("default"))
+	router.Post("/items", namedHandler("secure"), Scheme("https"))
+	router.Use("/items", namedHandler("mobile"), MatchFunc(func(r *http.Request) bool {
+		return r.UserAgent() == "mobile"
+	}))
+
+	cases := []struct {
+		name   string
+		method string
+		url    string
+		header http.Header
+		tls    bool
+		status int
+		body   string
+	}{
+		{"header value", http.MethodGet, "/items", http.Header{"X-Api-Version": {"2"}}, false, http.StatusOK, "v2"},
+		{"header regexp", http.MethodGet, "/items", http.Header{"X-Api-Version": {"3.1-beta"}}, false, http.StatusOK, "beta"},
+		{"unmatched header", http.MethodGet, "/items", http.Header{"X-Api-Version": {"4"}}, false, http.StatusOK, "default"},
+		{"query presence", http.MethodGet, "/items?q=", nil, false, http.StatusOK, "search"},
+		{"query value", http.MethodGet, "/items?sort=name", nil, false, http.StatusOK, "sorted"},
+		{"unmatched query", http.MethodGet, "/items?sort=date", nil, false, http.StatusOK, "default"},
+		{"first registered wins", http.MethodGet, "/items?q=a", http.Header{"X-Api-Version": {"2"}}, false, http.StatusOK, "v2"},
+		{"scheme", http.MethodPost, "/items", nil, true, http.StatusOK, "secure"},
+		{"unmatched scheme", http.MethodPost, "/items", nil, false, http.StatusNotFound, ""},
+		{"func", http.MethodPut, "/items", http.Header{"User-Agent": {"mobile"}}, false, http.StatusOK, "mobile"},
+		{"method fallback", http.MethodPost, "/items", http.Header{"User-Agent": {"mobile"}}, false, http.StatusOK, "mobile"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request := httptest.NewRequest(c.method, c.url, nil)
+			for k, v := range c.header {
+				request.Header[k] = v
+			}
+			if c.tls {
+				request.TLS = &tls.ConnectionState{}
+			}
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assertStatus(t, response, c.status)
+			assertBody(t, response, c.body)
+		})
+	}
+
+	t.Run("lists routes in the order they are tried", func(t *testing.T) {
+		var got []string
+		for _, rt := range router.Routes() {
+			if rt.Method == MethodGet {
+				rec := httptest.NewRecorder()
+				rt.Handler().ServeHTTP(rec, nil)
+				got = append(got, rec.Body.String())
+			}
+		}
+		want := []string{"v2", "beta", "search", "sorted", "default"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, but want %v", got, want)
+		}
+	})
+
+	t.Run("panics on a second route without matchers", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		router.Get("/items", namedHandler("again"))
+	})
+
+	t.Run("leaves rejected methods out of 405 replies", func(t *testing.T) {
+		router := NewRouter()
+		router.HandleMethodNotAllowed = true
+		router.Get("/things", namedHandler("a"), Header("X-A", "1"))
+		router.Post("/things", namedHandler("post"), Header("X-B", "1"))
+		router.Delete("/things", namedHandler("delete"))
+
+		cases := []struct {
+			name   string
+			method string
+			header string
+			status int
+			allow  string
+		}{
+			{"own method rejected", http.MethodGet, "", http.StatusMethodNotAllowed, "DELETE"},
+			{"other methods rejected", http.MethodPut, "", http.StatusMethodNotAllowed, "DELETE"},
+			{"other method accepted", http.MethodPut, "X-A", http.StatusMethodNotAllowed, "DELETE, GET, HEAD"},
+		}
+
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				request := httptest.NewRequest(c.method, "/things", nil)
+				if c.header != "" {
+					request.Header.Set(c.header, "1")
+				}
+				response := httptest.NewRecorder()
+
+				router.ServeHTTP(response, request)
+
+				assertStatus(t, response, c.status)
+				if got := response.Header().Get("Allow"); got != c.allow {
+					t.Errorf("got Allow %q, but want %q", got, c.allow)
+				}
+			})
+		}
+	})
+
+	t.Run("replies 404 when no method would serve", func(t *testing.T) {
+		router := NewRouter()
+		router.HandleMethodNotAllowed = true
+		router.Get("/things", namedHandler("a"), Header("X-A", "1"))
+
+		request := httptest.NewRequest(http.MethodGet, "/things", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusNotFound)
+	})
+}
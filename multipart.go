@@ -0,0 +1,435 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FileStorage stores the files uploaded through multipart requests.
+type FileStorage interface {
+	// Save stores the content read from r, returning the key
+	// that identifies it. The filename is already sanitized.
+	Save(filename string, r io.Reader) (key string, err error)
+	// Open returns the content stored under the key.
+	Open(key string) (io.ReadCloser, error)
+	// Remove deletes the content stored under the key.
+	Remove(key string) error
+}
+
+// DirStorage stores uploaded files in a local directory.
+type DirStorage struct {
+	Dir string
+}
+
+func (s DirStorage) Save(filename string, r io.Reader) (string, error) {
+	f, err := os.CreateTemp(s.Dir, "upload-*"+filepath.Ext(filename))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	key := filepath.Base(f.Name())
+	if _, err := io.Copy(f, r); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return key, nil
+}
+
+func (s DirStorage) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || key == ".." {
+		return "", fmt.Errorf("router: invalid storage key %q", key)
+	}
+	return filepath.Join(s.Dir, key), nil
+}
+
+func (s DirStorage) Open(key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (s DirStorage) Remove(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
+
+// MemoryStorage stores uploaded files in memory.
+// The zero value is ready to use.
+type MemoryStorage struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+	seq   int
+}
+
+func (s *MemoryStorage) Save(filename string, r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.files == nil {
+		s.files = make(map[string][]byte)
+	}
+	s.seq++
+	key := strconv.Itoa(s.seq) + "-" + filename
+	s.files[key] = data
+	return key, nil
+}
+
+func (s *MemoryStorage) Open(key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.files[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *MemoryStorage) Remove(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.files[key]; !ok {
+		return os.ErrNotExist
+	}
+	delete(s.files, key)
+	return nil
+}
+
+// UploadedFile describes a file uploaded through a multipart request.
+type UploadedFile struct {
+	Field       string // the form field name
+	Filename    string // the sanitized file name given by the client
+	ContentType string // the content type sniffed from the file content
+	Size        int64
+	Key         string // the key given by the storage
+	storage     FileStorage
+}
+
+// Open returns the stored file content.
+func (f *UploadedFile) Open() (io.ReadCloser, error) {
+	return f.storage.Open(f.Key)
+}
+
+// FileTooLargeError records an uploaded file bigger than allowed.
+type FileTooLargeError struct {
+	Field    string
+	Filename string
+	Limit    int64
+}
+
+func (e *FileTooLargeError) Error() string {
+	return fmt.Sprintf("router: file %q of field %q is bigger than %d bytes", e.Filename, e.Field, e.Limit)
+}
+
+// StatusCode returns HTTP 413.
+func (e *FileTooLargeError) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// Holds the max size of non file values when not configured.
+const defaultMaxValueSize = 1 << 20
+
+// MultipartOptions configures ParseMultipart.
+type MultipartOptions struct {
+	// Stores the uploaded files, defaults to a new MemoryStorage.
+	Storage FileStorage
+	// Holds the max size, in bytes, of each file,
+	// zero means no limit.
+	MaxFileSize int64
+	// Holds the max size, in bytes, of all files together,
+	// zero means no limit.
+	MaxTotalSize int64
+	// Holds the max size, in bytes, of each non file value,
+	// zero means 1 MB.
+	MaxValueSize int64
+	// Holds the content types that files may have, like image/png,
+	// or image/* to allow every image type. Empty allows any type.
+	AllowedTypes []string
+}
+
+var uploadedFileType = reflect.TypeOf((*UploadedFile)(nil))
+
+// ParseMultipart reads a multipart/form-data body part by part, storing
+// files into the configured storage as they are read, and fills the struct
+// pointed by dst. Non file values are bound into fields with form tags, like
+// Bind does, and files are set into fields of type *UploadedFile or
+// []*UploadedFile, also named by form tags. The struct is then checked by
+// Validate.
+//
+// File content types are sniffed from their first bytes, through
+// http.DetectContentType, and file names are sanitized, being reduced to
+// their base names, without control characters or leading dots.
+//
+// Disallowed content types fail with *UnsupportedMediaTypeError, and files
+// bigger than allowed fail with *FileTooLargeError, or *BodyTooLargeError
+// when the total size is exceeded. On failures the stored files are removed.
+func (r *Request) ParseMultipart(dst any, opts MultipartOptions) error {
+	v := getPtrValue(dst)
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("router: cannot parse multipart into %T, it must point to a struct", dst)
+	}
+
+	if opts.Storage == nil {
+		opts.Storage = &MemoryStorage{}
+	}
+	if opts.MaxValueSize == 0 {
+		opts.MaxValueSize = defaultMaxValueSize
+	}
+
+	r.rewindBody()
+	mr, err := r.MultipartReader()
+	if err != nil {
+		if errors.Is(err, http.ErrNotMultipart) {
+			mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			return &UnsupportedMediaTypeError{mt}
+		}
+		return err
+	}
+
+	up := &uploader{opts: opts, values: make(map[string][]string), files: make(map[string][]*UploadedFile)}
+
+	if err := up.read(mr); err != nil {
+		up.removeAll()
+		return err
+	}
+
+	var errs BindErrors
+	bindStruct(v, "", func(source, name string) []string {
+		if source == "form" {
+			return up.values[name]
+		}
+		return nil
+	}, &errs)
+	bindFiles(v, up.files)
+	if len(errs) > 0 {
+		up.removeAll()
+		return errs
+	}
+
+	if err := Validate(dst); err != nil {
+		up.removeAll()
+		return err
+	}
+	return nil
+}
+
+type uploader struct {
+	opts   MultipartOptions
+	values map[string][]string
+	files  map[string][]*UploadedFile
+	total  int64
+}
+
+func (up *uploader) read(mr *multipart.Reader) error {
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("router: cannot read multipart body, %w", bodyError(err))
+		}
+
+		name := part.FormName()
+		if name == "" {
+			part.Close()
+			continue
+		}
+
+		if part.FileName() == "" {
+			err = up.readValue(name, part)
+		} else {
+			err = up.readFile(name, part.FileName(), part)
+		}
+		part.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (up *uploader) readValue(name string, part io.Reader) error {
+	raw, err := io.ReadAll(io.LimitReader(part, up.opts.MaxValueSize+1))
+	if err != nil {
+		return fmt.Errorf("router: cannot read multipart body, %w", bodyError(err))
+	}
+	if int64(len(raw)) > up.opts.MaxValueSize {
+		return &BodyTooLargeError{Limit: up.opts.MaxValueSize}
+	}
+	up.values[name] = append(up.values[name], string(raw))
+	return nil
+}
+
+func (up *uploader) readFile(name, filename string, part io.Reader) error {
+	filename = sanitizeFilename(filename)
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return fmt.Errorf("router: cannot read multipart body, %w", bodyError(err))
+	}
+	head = head[:n]
+
+	ct := http.DetectContentType(head)
+	if !up.allowed(ct) {
+		mt, _, _ := mime.ParseMediaType(ct)
+		return &UnsupportedMediaTypeError{mt}
+	}
+
+	limit, tooLarge := int64(-1), error(nil)
+	if up.opts.MaxFileSize > 0 {
+		limit, tooLarge = up.opts.MaxFileSize, &FileTooLargeError{name, filename, up.opts.MaxFileSize}
+	}
+	if up.opts.MaxTotalSize > 0 {
+		if rest := up.opts.MaxTotalSize - up.total; limit < 0 || rest < limit {
+			limit, tooLarge = rest, &BodyTooLargeError{Limit: up.opts.MaxTotalSize}
+		}
+	}
+
+	cr := &countingReader{r: io.MultiReader(bytes.NewReader(head), part)}
+	var src io.Reader = cr
+	if limit >= 0 {
+		src = io.LimitReader(cr, limit+1)
+	}
+
+	key, err := up.opts.Storage.Save(filename, src)
+	if err != nil {
+		return fmt.Errorf("router: cannot store file %q, %w", filename, bodyError(err))
+	}
+
+	f := &UploadedFile{
+		Field:       name,
+		Filename:    filename,
+		ContentType: ct,
+		Size:        cr.n,
+		Key:         key,
+		storage:     up.opts.Storage,
+	}
+	up.files[name] = append(up.files[name], f)
+	up.total += cr.n
+
+	if limit >= 0 && cr.n > limit {
+		return tooLarge
+	}
+	return nil
+}
+
+func (up *uploader) allowed(ct string) bool {
+	if len(up.opts.AllowedTypes) == 0 {
+		return true
+	}
+	mt, _, _ := mime.ParseMediaType(ct)
+	for _, t := range up.opts.AllowedTypes {
+		if mediaTypeMatches(t, mt) {
+			return true
+		}
+	}
+	return false
+}
+
+func (up *uploader) removeAll() {
+	for _, files := range up.files {
+		for _, f := range files {
+			up.opts.Storage.Remove(f.Key)
+		}
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// sanitizeFilename reduces the client given name to a base name
+// that is safe to be used in file systems.
+func sanitizeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`<>:"|?*`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+
+	if len(name) > 255 {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		// Cut on a rune boundary, so the name stays valid UTF-8.
+		n := 255 - len(ext)
+		for n > 0 && !utf8.RuneStart(name[n]) {
+			n--
+		}
+		name = name[:n] + ext
+	}
+	if name == "" {
+		return "file"
+	}
+	return name
+}
+
+// bindFiles sets the uploaded files into the fields of type *UploadedFile
+// and []*UploadedFile, named by their form tags.
+func bindFiles(v reflect.Value, files map[string][]*UploadedFile) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		if isNestedStruct(f) {
+			bindFiles(v.Field(i), files)
+			continue
+		}
+
+		name, ok := f.Tag.Lookup("form")
+		if !ok || len(files[name]) == 0 {
+			continue
+		}
+
+		switch f.Type {
+		case uploadedFileType:
+			v.Field(i).Set(reflect.ValueOf(files[name][0]))
+		case reflect.SliceOf(uploadedFileType):
+			v.Field(i).Set(reflect.ValueOf(files[name]))
+		}
+	}
+}
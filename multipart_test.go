@@ -0,0 +1,219 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+var pngHeader = "\x89PNG\r\n\x1a\n"
+
+type multipartField struct {
+	name, filename, content string
+}
+
+func newMultipartRequest(fields ...multipartField) *Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, f := range fields {
+		if f.filename == "" {
+			w.WriteField(f.name, f.content)
+			continue
+		}
+		fw, _ := w.CreateFormFile(f.name, f.filename)
+		io.WriteString(fw, f.content)
+	}
+	w.Close()
+
+	request := newRequest(http.MethodPost, newDummyURI("/uploads"), body.String())
+	request.Header.Set("Content-Type", w.FormDataContentType())
+	return request
+}
+
+func readUploaded(t testing.TB, f *UploadedFile) string {
+	t.Helper()
+
+	rc, err := f.Open()
+	assertNoError(t, err)
+	defer rc.Close()
+
+	raw, _ := io.ReadAll(rc)
+	return string(raw)
+}
+
+type upload struct {
+	Title  string          `form:"title" validate:"required"`
+	Count  int             `form:"count"`
+	Avatar *UploadedFile   `form:"avatar"`
+	Docs   []*UploadedFile `form:"docs"`
+}
+
+func TestParseMultipart(t *testing.T) {
+
+	t.Run("binds values and files", func(t *testing.T) {
+		request := newMultipartRequest(
+			multipartField{"title", "", "holiday"},
+			multipartField{"count", "", "2"},
+			multipartField{"avatar", "me.png", pngHeader + "pixels"},
+			multipartField{"docs", "a.txt", "first"},
+			multipartField{"docs", "b.txt", "second"},
+		)
+
+		var got upload
+		assertNoError(t, request.ParseMultipart(&got, MultipartOptions{}))
+
+		if got.Title != "holiday" || got.Count != 2 {
+			t.Errorf("got %+v, but want title and count bound", got)
+		}
+		if got.Avatar == nil {
+			t.Fatal("expected avatar file")
+		}
+		if got.Avatar.Filename != "me.png" || got.Avatar.ContentType != "image/png" || got.Avatar.Size != int64(len(pngHeader)+6) {
+			t.Errorf("got %+v, but want me.png of type image/png", got.Avatar)
+		}
+		if content := readUploaded(t, got.Avatar); content != pngHeader+"pixels" {
+			t.Errorf("got %q, but want the avatar content", content)
+		}
+		if len(got.Docs) != 2 || readUploaded(t, got.Docs[1]) != "second" {
+			t.Errorf("got %d docs, but want 2", len(got.Docs))
+		}
+		if got.Docs[0].ContentType != "text/plain; charset=utf-8" {
+			t.Errorf(`got %q, but want "text/plain; charset=utf-8"`, got.Docs[0].ContentType)
+		}
+	})
+
+	t.Run("stores files into a directory", func(t *testing.T) {
+		dir := t.TempDir()
+		request := newMultipartRequest(
+			multipartField{"title", "", "holiday"},
+			multipartField{"avatar", "me.png", pngHeader},
+		)
+
+		var got upload
+		assertNoError(t, request.ParseMultipart(&got, MultipartOptions{Storage: DirStorage{dir}}))
+
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 1 || entries[0].Name() != got.Avatar.Key {
+			t.Fatalf("got %v, but want the stored avatar", entries)
+		}
+		if content := readUploaded(t, got.Avatar); content != pngHeader {
+			t.Errorf("got %q, but want the avatar content", content)
+		}
+
+		if _, err := (DirStorage{dir}).Open("../" + got.Avatar.Key); err == nil {
+			t.Error("expected error on key out of the directory")
+		}
+	})
+
+	t.Run("fails on files bigger than allowed", func(t *testing.T) {
+		storage := &MemoryStorage{}
+		request := newMultipartRequest(
+			multipartField{"title", "", "holiday"},
+			multipartField{"docs", "a.txt", "small"},
+			multipartField{"docs", "b.txt", strings.Repeat("a", 20)},
+		)
+
+		var got upload
+		err := request.ParseMultipart(&got, MultipartOptions{Storage: storage, MaxFileSize: 10})
+
+		var tooLarge *FileTooLargeError
+		if !errors.As(err, &tooLarge) {
+			t.Fatalf("got %v, but want *FileTooLargeError", err)
+		}
+		if tooLarge.Field != "docs" || tooLarge.Filename != "b.txt" || tooLarge.Limit != 10 {
+			t.Errorf("got %+v, but want b.txt of docs", tooLarge)
+		}
+		if errorStatus(err) != http.StatusRequestEntityTooLarge {
+			t.Errorf("got status %d, but want 413", errorStatus(err))
+		}
+		if len(storage.files) != 0 {
+			t.Errorf("got %d stored files, but want them removed", len(storage.files))
+		}
+	})
+
+	t.Run("fails when files together are bigger than allowed", func(t *testing.T) {
+		request := newMultipartRequest(
+			multipartField{"docs", "a.txt", strings.Repeat("a", 8)},
+			multipartField{"docs", "b.txt", strings.Repeat("b", 8)},
+		)
+
+		var got upload
+		err := request.ParseMultipart(&got, MultipartOptions{MaxFileSize: 10, MaxTotalSize: 12})
+
+		var tooLarge *BodyTooLargeError
+		if !errors.As(err, &tooLarge) || tooLarge.Limit != 12 {
+			t.Errorf("got %v, but want *BodyTooLargeError of 12 bytes", err)
+		}
+	})
+
+	t.Run("fails on disallowed content types", func(t *testing.T) {
+		request := newMultipartRequest(
+			multipartField{"avatar", "me.png", "<html><body>not an image"},
+		)
+
+		var got upload
+		err := request.ParseMultipart(&got, MultipartOptions{AllowedTypes: []string{"image/*"}})
+
+		var unsupported *UnsupportedMediaTypeError
+		if !errors.As(err, &unsupported) || unsupported.MediaType != "text/html" {
+			t.Errorf(`got %v, but want *UnsupportedMediaTypeError of "text/html"`, err)
+		}
+	})
+
+	t.Run("fails on bodies other than multipart", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/uploads"), "{}")
+		request.Header.Set("Content-Type", "application/json")
+
+		var got upload
+		err := request.ParseMultipart(&got, MultipartOptions{})
+
+		var unsupported *UnsupportedMediaTypeError
+		if !errors.As(err, &unsupported) || unsupported.MediaType != "application/json" {
+			t.Errorf("got %v, but want *UnsupportedMediaTypeError", err)
+		}
+	})
+
+	t.Run("validates the struct", func(t *testing.T) {
+		storage := &MemoryStorage{}
+		request := newMultipartRequest(
+			multipartField{"count", "", "1"},
+			multipartField{"avatar", "me.png", pngHeader},
+		)
+
+		var got upload
+		err := request.ParseMultipart(&got, MultipartOptions{Storage: storage})
+
+		var verrs ValidationErrors
+		if !errors.As(err, &verrs) {
+			t.Errorf("got %v, but want ValidationErrors", err)
+		}
+		if len(storage.files) != 0 {
+			t.Errorf("got %d stored files, but want them removed", len(storage.files))
+		}
+	})
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	cases := map[string]string{
+		"photo.png":                       "photo.png",
+		"../../etc/passwd":                "passwd",
+		`C:\Users\me\report.pdf`:          "report.pdf",
+		".htaccess":                       "htaccess",
+		"bad\x00na<me>.txt":               "badname.txt",
+		"   ":                             "file",
+		"..":                              "file",
+		strings.Repeat("a", 300) + ".txt": strings.Repeat("a", 251) + ".txt",
+		strings.Repeat("é", 300) + ".txt": strings.Repeat("é", 125) + ".txt",
+	}
+
+	for name, want := range cases {
+		if got := sanitizeFilename(name); got != want {
+			t.Errorf("got %q from %q, but want %q", got, name, want)
+		}
+	}
+}
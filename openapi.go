@@ -0,0 +1,570 @@
+package router
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const openAPIVersion = "3.1.0"
+
+// OpenAPI is an OpenAPI 3 document.
+type OpenAPI struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       OpenAPIInfo          `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+// OpenAPIInfo holds the metadata about the API.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations available on a single path.
+type PathItem struct {
+	Summary     string       `json:"summary,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Get         *Operation   `json:"get,omitempty"`
+	Put         *Operation   `json:"put,omitempty"`
+	Post        *Operation   `json:"post,omitempty"`
+	Delete      *Operation   `json:"delete,omitempty"`
+	Options     *Operation   `json:"options,omitempty"`
+	Head        *Operation   `json:"head,omitempty"`
+	Patch       *Operation   `json:"patch,omitempty"`
+	Trace       *Operation   `json:"trace,omitempty"`
+	Parameters  []*Parameter `json:"parameters,omitempty"`
+}
+
+// Operation returns the operation of the path item for the given method,
+// or nil if there is none.
+func (pi *PathItem) Operation(method string) *Operation {
+	if op := pi.operationRef(method); op != nil {
+		return *op
+	}
+	return nil
+}
+
+func (pi *PathItem) operationRef(method string) **Operation {
+	switch method {
+	case http.MethodGet:
+		return &pi.Get
+	case http.MethodPut:
+		return &pi.Put
+	case http.MethodPost:
+		return &pi.Post
+	case http.MethodDelete:
+		return &pi.Delete
+	case http.MethodOptions:
+		return &pi.Options
+	case http.MethodHead:
+		return &pi.Head
+	case http.MethodPatch:
+		return &pi.Patch
+	case http.MethodTrace:
+		return &pi.Trace
+	}
+	return nil
+}
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	In          string  `json:"in,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes the body accepted by an operation.
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// Response describes a single response of an operation.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a given content type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds the reusable objects of the document.
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas,omitempty"`
+	Parameters map[string]*Parameter `json:"parameters,omitempty"`
+}
+
+// Schema is a JSON Schema as used by OpenAPI 3.1.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 SchemaType         `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Not                  *Schema            `json:"not,omitempty"`
+}
+
+// UnmarshalJSON accepts boolean schemas too, where true
+// allows everything and false allows nothing.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{Not: &Schema{}}
+		return nil
+	}
+	type plain Schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// SchemaType holds the JSON types allowed by a schema, it's encoded as
+// a single string when holding only one type, as an array otherwise.
+type SchemaType []string
+
+func (st SchemaType) MarshalJSON() ([]byte, error) {
+	if len(st) == 1 {
+		return json.Marshal(st[0])
+	}
+	return json.Marshal([]string(st))
+}
+
+func (st *SchemaType) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*st = SchemaType{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*st = many
+	return nil
+}
+
+// Has reports whether the given JSON type is allowed.
+func (st SchemaType) Has(typ string) bool {
+	for _, t := range st {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteDoc holds the documentation of a route used to
+// generate the OpenAPI document.
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Request     reflect.Type
+	Responses   map[int]reflect.Type
+}
+
+// Summary sets a short summary of what the route does.
+func Summary(summary string) RouteOption {
+	return func(rt *Route) {
+		rt.Doc.Summary = summary
+	}
+}
+
+// Description sets a verbose explanation of the route behavior.
+func Description(description string) RouteOption {
+	return func(rt *Route) {
+		rt.Doc.Description = description
+	}
+}
+
+// Tags adds tags for logical grouping of routes.
+func Tags(tags ...string) RouteOption {
+	return func(rt *Route) {
+		rt.Doc.Tags = append(rt.Doc.Tags, tags...)
+	}
+}
+
+// Accepts documents the request body as the type of v.
+func Accepts(v any) RouteOption {
+	return func(rt *Route) {
+		rt.Doc.Request = reflect.TypeOf(v)
+	}
+}
+
+// Returns documents the response with the given status code, with
+// a body of the type of v. A nil v documents a response without body.
+func Returns(status int, v any) RouteOption {
+	return func(rt *Route) {
+		if rt.Doc.Responses == nil {
+			rt.Doc.Responses = make(map[int]reflect.Type)
+		}
+		rt.Doc.Responses[status] = reflect.TypeOf(v)
+	}
+}
+
+var docMethods = []string{MethodGet, MethodPost, MethodPut, MethodDelete}
+
+// OpenAPI generates an OpenAPI 3.1 document from the registered routes.
+// Routes registered through Use are documented for GET, POST, PUT and
+// DELETE methods, unless a route was registered specifically to them.
+// Host based patterns are documented only by their paths. Versions
+// found by VersionFromPath are documented by paths of their own, like
+// /v2/items, otherwise routes of every version share the operation.
+func (ro *Router) OpenAPI(info OpenAPIInfo) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: openAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+	}
+	sg := &schemaGenerator{schemas: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+
+	routes := ro.Routes()
+
+	prefix, pathVersions := versionPrefix(ro.Versioning)
+
+	registered := make(map[string]bool)
+	for _, rt := range routes {
+		registered[rt.Method+" "+rt.Pattern] = true
+	}
+
+	for _, rt := range routes {
+		if rt.Hidden {
+			continue
+		}
+
+		p := rt.compiled().docPath()
+		if pathVersions && rt.Version != "" {
+			p = "/" + prefix + rt.Version + p
+		}
+		item, ok := doc.Paths[p]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[p] = item
+		}
+
+		methods := []string{rt.Method}
+		if rt.Method == MethodAll {
+			methods = methods[:0]
+			for _, m := range docMethods {
+				if !registered[m+" "+rt.Pattern] {
+					methods = append(methods, m)
+				}
+			}
+		}
+
+		for _, m := range methods {
+			// Routes sharing the path and method, like the ones told
+			// apart by Consumes, share the operation of the first one,
+			// which documents the media types of all of them.
+			if op := item.operationRef(m); *op == nil {
+				*op = sg.operation(rt)
+			} else {
+				sg.merge(*op, rt)
+			}
+		}
+	}
+
+	if len(sg.schemas) > 0 {
+		doc.Components = &Components{Schemas: sg.schemas}
+	}
+
+	return doc
+}
+
+// ServeOpenAPI registers a GET route on the given path that replies
+// with the OpenAPI document generated from the router routes.
+func (ro *Router) ServeOpenAPI(path string, info OpenAPIInfo) {
+	ro.GetFunc(path, func(w ResponseWriter, r *Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ro.OpenAPI(info))
+	}, Hidden())
+}
+
+func docPathParams(names []string) []*Parameter {
+	var params []*Parameter
+	for _, name := range names {
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: SchemaType{"string"}},
+		})
+	}
+	return params
+}
+
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func (sg *schemaGenerator) operation(rt *Route) *Operation {
+	op := &Operation{
+		OperationID: rt.Name,
+		Summary:     rt.Doc.Summary,
+		Description: rt.Doc.Description,
+		Tags:        rt.Doc.Tags,
+		Parameters:  docPathParams(rt.compiled().params),
+		Responses:   make(map[string]*Response),
+		Deprecated:  !rt.Deprecated.IsZero(),
+	}
+
+	if rt.Doc.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  sg.content(rt.Doc.Request, rt.Consumes),
+		}
+	}
+
+	for status, t := range rt.Doc.Responses {
+		res := &Response{Description: http.StatusText(status)}
+		if t != nil {
+			res.Content = sg.content(t, rt.Produces)
+		}
+		op.Responses[strconv.Itoa(status)] = res
+	}
+
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &Response{Description: "Default response"}
+	}
+
+	return op
+}
+
+// merge adds the request and response bodies of rt to op, which
+// documents another route of the same path and method.
+func (sg *schemaGenerator) merge(op *Operation, rt *Route) {
+	other := sg.operation(rt)
+	op.Deprecated = op.Deprecated && other.Deprecated
+
+	if other.RequestBody != nil {
+		if op.RequestBody == nil {
+			op.RequestBody = other.RequestBody
+		} else {
+			mergeContent(op.RequestBody.Content, other.RequestBody.Content)
+		}
+	}
+
+	if len(rt.Doc.Responses) == 0 {
+		return
+	}
+	delete(op.Responses, "default")
+	for status, res := range other.Responses {
+		cur, ok := op.Responses[status]
+		switch {
+		case !ok:
+			op.Responses[status] = res
+		case cur.Content == nil:
+			cur.Content = res.Content
+		default:
+			mergeContent(cur.Content, res.Content)
+		}
+	}
+}
+
+// Adds the media types of src missing from dst.
+func mergeContent(dst, src map[string]*MediaType) {
+	for mt, c := range src {
+		if _, ok := dst[mt]; !ok {
+			dst[mt] = c
+		}
+	}
+}
+
+// content describes t in each one of the media types, which
+// default to JSON.
+func (sg *schemaGenerator) content(t reflect.Type, mediaTypes []string) map[string]*MediaType {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+
+	schema := sg.schema(t)
+	content := make(map[string]*MediaType, len(mediaTypes))
+	for _, mt := range mediaTypes {
+		content[mt] = &MediaType{Schema: schema}
+	}
+	return content
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+var schemaNameCleaner = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// schema reflects the given type into a JSON Schema. Named struct types
+// are placed into the components and referenced, so recursive types
+// are supported.
+func (sg *schemaGenerator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: SchemaType{"string"}, Format: "date-time"}
+	case t.Implements(textMarshalerType):
+		return &Schema{Type: SchemaType{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: SchemaType{"integer"}, Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: SchemaType{"integer"}, Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: SchemaType{"number"}, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}, Format: "double"}
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaType{"string"}, Format: "byte"}
+		}
+		return &Schema{Type: SchemaType{"array"}, Items: sg.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: SchemaType{"object"}, AdditionalProperties: sg.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return sg.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + sg.define(t)}
+	}
+
+	return &Schema{}
+}
+
+// nullable makes the schema allow null too, the way of OpenAPI 3.1,
+// adding "null" to its types, or to a reference through anyOf.
+func nullable(s *Schema) *Schema {
+	switch {
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: SchemaType{"null"}}}}
+	case len(s.Type) > 0:
+		s.Type = append(s.Type, "null")
+	}
+	return s
+}
+
+func (sg *schemaGenerator) define(t reflect.Type) string {
+	if name, ok := sg.names[t]; ok {
+		return name
+	}
+
+	name := schemaNameCleaner.ReplaceAllString(t.Name(), "_")
+	for i := 2; sg.schemas[name] != nil; i++ {
+		name = schemaNameCleaner.ReplaceAllString(t.Name(), "_") + strconv.Itoa(i)
+	}
+
+	sg.names[t] = name
+	sg.schemas[name] = &Schema{}
+	*sg.schemas[name] = *sg.object(t)
+
+	return name
+}
+
+func (sg *schemaGenerator) object(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:       SchemaType{"object"},
+		Properties: make(map[string]*Schema),
+	}
+	sg.fields(s, t, make(map[reflect.Type]bool))
+	sort.Strings(s.Required)
+	return s
+}
+
+// fields adds the fields of t to s, following embedded structs, each
+// once, since a struct may embed a pointer to itself.
+func (sg *schemaGenerator) fields(s *Schema, t reflect.Type, seen map[reflect.Type]bool) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				sg.fields(s, ft, seen)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		if f.Type.Kind() == reflect.Pointer {
+			s.Properties[name] = nullable(sg.schema(f.Type))
+			continue
+		}
+		s.Properties[name] = sg.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
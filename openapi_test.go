@@ -0,0 +1,242 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type docPet struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Tag      string    `json:"tag,omitempty"`
+	Born     time.Time `json:"born"`
+	Owner    *docPet   `json:"owner"`
+	internal string
+}
+
+func TestOpenAPI(t *testing.T) {
+
+	router := NewRouter()
+	router.Get("/pets", dummyHandler, Name("listPets"), Tags("pets"), Returns(http.StatusOK, []docPet{}))
+	router.Post("/pets", dummyHandler, Summary("Create a pet"), Accepts(docPet{}), Returns(http.StatusCreated, docPet{}))
+	router.Use("/pets/{id}", dummyHandler)
+	router.Delete("/pets/{id}", dummyHandler, Returns(http.StatusNoContent, nil))
+	router.Get("site.com/owners/{owner}/pets/{pet}", dummyHandler)
+	router.Get("/internal", dummyHandler, Hidden())
+
+	doc := router.OpenAPI(OpenAPIInfo{Title: "Pets", Version: "1.0"})
+
+	t.Run("documents every visible path", func(t *testing.T) {
+		var got []string
+		for p := range doc.Paths {
+			got = append(got, p)
+		}
+
+		want := []string{"/owners/{owner}/pets/{pet}", "/pets", "/pets/{id}"}
+		assertSameStrings(t, got, want)
+	})
+
+	t.Run("documents route metadata", func(t *testing.T) {
+		op := doc.Paths["/pets"].Get
+
+		if op.OperationID != "listPets" {
+			t.Errorf("got operation id %q, but want %q", op.OperationID, "listPets")
+		}
+		if !reflect.DeepEqual(op.Tags, []string{"pets"}) {
+			t.Errorf("got tags %v, but want %v", op.Tags, []string{"pets"})
+		}
+
+		schema := op.Responses["200"].Content["application/json"].Schema
+		if !schema.Type.Has("array") || schema.Items.Ref != "#/components/schemas/docPet" {
+			t.Errorf("got response schema %+v, but want array of docPet", schema)
+		}
+
+		post := doc.Paths["/pets"].Post
+		if post.Summary != "Create a pet" {
+			t.Errorf("got summary %q, but want %q", post.Summary, "Create a pet")
+		}
+		if post.RequestBody == nil {
+			t.Fatal("didn't document request body")
+		}
+	})
+
+	t.Run("expands routes for all methods", func(t *testing.T) {
+		item := doc.Paths["/pets/{id}"]
+
+		for _, op := range []*Operation{item.Get, item.Post, item.Put, item.Delete} {
+			if op == nil {
+				t.Fatal("missing operation")
+			}
+		}
+
+		if _, ok := item.Delete.Responses["204"]; !ok {
+			t.Error("expected DELETE to keep its own registration")
+		}
+	})
+
+	t.Run("converts path params", func(t *testing.T) {
+		op := doc.Paths["/owners/{owner}/pets/{pet}"].Get
+
+		var got []string
+		for _, p := range op.Parameters {
+			if p.In != "path" || !p.Required {
+				t.Errorf("got param %+v, but want required path param", p)
+			}
+			got = append(got, p.Name)
+		}
+		assertSameStrings(t, got, []string{"owner", "pet"})
+	})
+
+	t.Run("reflects types into schemas", func(t *testing.T) {
+		pet := doc.Components.Schemas["docPet"]
+
+		var props []string
+		for p := range pet.Properties {
+			props = append(props, p)
+		}
+		assertSameStrings(t, props, []string{"born", "id", "name", "owner", "tag"})
+		assertSameStrings(t, pet.Required, []string{"born", "id", "name"})
+
+		if pet.Properties["born"].Format != "date-time" {
+			t.Errorf("got born format %q, but want date-time", pet.Properties["born"].Format)
+		}
+		owner := pet.Properties["owner"]
+		if len(owner.AnyOf) != 2 || owner.AnyOf[0].Ref != "#/components/schemas/docPet" || !owner.AnyOf[1].Type.Has("null") {
+			t.Errorf("got owner %+v, but want a nullable docPet reference", owner)
+		}
+	})
+
+	t.Run("makes pointer fields nullable", func(t *testing.T) {
+		type nullables struct {
+			Name  *string `json:"name"`
+			Count *int    `json:"count"`
+		}
+
+		router := NewRouter()
+		router.Get("/n", dummyHandler, Returns(http.StatusOK, nullables{}))
+		doc := router.OpenAPI(OpenAPIInfo{})
+
+		s := doc.Components.Schemas["nullables"]
+		if got := s.Properties["name"].Type; !reflect.DeepEqual(got, SchemaType{"string", "null"}) {
+			t.Errorf("got name type %v, but want string and null", got)
+		}
+		if got := s.Properties["count"].Type; !reflect.DeepEqual(got, SchemaType{"integer", "null"}) {
+			t.Errorf("got count type %v, but want integer and null", got)
+		}
+		raw, _ := json.Marshal(s)
+		if strings.Contains(string(raw), "nullable") {
+			t.Errorf("got %s, but want no nullable keyword", raw)
+		}
+	})
+
+	t.Run("follows structs embedding themselves once", func(t *testing.T) {
+		router := NewRouter()
+		router.Get("/self", dummyHandler, Returns(http.StatusOK, selfEmbedding{}))
+		doc := router.OpenAPI(OpenAPIInfo{})
+
+		s := doc.Components.Schemas["selfEmbedding"]
+		if _, ok := s.Properties["V"]; !ok || len(s.Properties) != 1 {
+			t.Errorf("got properties %v, but want V", s.Properties)
+		}
+	})
+}
+
+func TestOpenAPIVersions(t *testing.T) {
+	newRouter := func(versioning VersionFunc) *Router {
+		router := NewRouter()
+		router.Versioning = versioning
+		router.Get("/items", dummyHandler, Version("1"), Deprecated(time.Unix(0, 0)), Returns(http.StatusOK, docPet{}))
+		router.Get("/items", dummyHandler, Version("2"), Returns(http.StatusOK, []docPet{}))
+		router.Get("/health", dummyHandler)
+		return router
+	}
+
+	t.Run("documents path versions apart", func(t *testing.T) {
+		doc := newRouter(VersionFromPath("v")).OpenAPI(OpenAPIInfo{})
+
+		var got []string
+		for p := range doc.Paths {
+			got = append(got, p)
+		}
+		assertSameStrings(t, got, []string{"/health", "/v1/items", "/v2/items"})
+
+		if v1 := doc.Paths["/v1/items"].Get; !v1.Deprecated {
+			t.Error("expected v1 deprecated")
+		}
+		v2 := doc.Paths["/v2/items"].Get
+		if v2.Deprecated {
+			t.Error("expected v2 not deprecated")
+		}
+		if schema := v2.Responses["200"].Content["application/json"].Schema; !schema.Type.Has("array") {
+			t.Errorf("got v2 schema %+v, but want array of docPet", schema)
+		}
+	})
+
+	t.Run("shares the operation of other versions", func(t *testing.T) {
+		doc := newRouter(VersionFromHeader("X-API-Version")).OpenAPI(OpenAPIInfo{})
+
+		op := doc.Paths["/items"].Get
+		if op == nil {
+			t.Fatalf("got paths %v, but want /items", doc.Paths)
+		}
+		if op.Deprecated {
+			t.Error("expected the operation not deprecated, as v2 isn't")
+		}
+	})
+}
+
+type selfEmbedding struct {
+	*selfEmbedding
+	V int
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	router := NewRouter()
+	router.Get("/pets", dummyHandler)
+	router.ServeOpenAPI("/openapi.json", OpenAPIInfo{Title: "Pets", Version: "1.0"})
+
+	request, _ := http.NewRequest(http.MethodGet, newDummyURI("/openapi.json"), nil)
+	response := httptest.NewRecorder()
+
+	router.ServeHTTP(response, request)
+
+	assertStatus(t, response, http.StatusOK)
+
+	var doc OpenAPI
+	if err := json.NewDecoder(response.Body).Decode(&doc); err != nil {
+		t.Fatalf("cannot decode document, %v", err)
+	}
+
+	if doc.OpenAPI != "3.1.0" || doc.Info.Title != "Pets" {
+		t.Errorf("got document %+v", doc)
+	}
+	if _, ok := doc.Paths["/openapi.json"]; ok {
+		t.Error("expected document route to be hidden")
+	}
+	if _, ok := doc.Paths["/pets"]; !ok {
+		t.Error("expected /pets to be documented")
+	}
+}
+
+func assertSameStrings(t testing.TB, got, want []string) {
+	t.Helper()
+
+	set := make(map[string]int)
+	for _, s := range got {
+		set[s]++
+	}
+	for _, s := range want {
+		set[s]--
+	}
+	for _, n := range set {
+		if n != 0 {
+			t.Errorf("got %v, but want %v", got, want)
+			return
+		}
+	}
+}
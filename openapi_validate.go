@@ -0,0 +1,605 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// LoadOpenAPI reads a JSON encoded OpenAPI 3 document.
+func LoadOpenAPI(r io.Reader) (*OpenAPI, error) {
+	var doc OpenAPI
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("router: cannot load OpenAPI document, %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("router: unsupported OpenAPI version %q", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// Violation describes a single way that a request disagrees
+// with its operation in the OpenAPI document.
+type Violation struct {
+	In      string `json:"in"`
+	Name    string `json:"name,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	s := v.In
+	if v.Name != "" {
+		s += " " + v.Name
+	}
+	if v.Path != "" {
+		s += " " + v.Path
+	}
+	return s + ": " + v.Message
+}
+
+// RequestValidationError holds every violation found in a request.
+type RequestValidationError struct {
+	Message    string      `json:"message"`
+	Violations []Violation `json:"violations"`
+}
+
+func (e *RequestValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return "router: " + e.Message + ", " + strings.Join(msgs, "; ")
+}
+
+func (e *RequestValidationError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+var specMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+type operationKey struct {
+	route  *Route
+	method string
+}
+
+type boundOperation struct {
+	params []*Parameter
+	body   *RequestBody
+}
+
+// ValidateRequests binds the operations of the document to the routes
+// of the router, by their paths and methods, and returns a middleware
+// that rejects, with HTTP 400 and the list of violations, the requests
+// disagreeing with the parameters and JSON bodies of their operations.
+//
+// The document paths must be written exactly like the router patterns,
+// host based patterns are bound by their paths. It fails if any operation
+// has no route to bind to, or if the document has unresolvable references.
+func ValidateRequests(ro *Router, doc *OpenAPI) (Middleware, error) {
+	v := &specValidator{doc: doc}
+
+	byPath := make(map[string]map[string][]*Route)
+	for _, rt := range ro.Routes() {
+		p := rt.compiled().docPath()
+		if byPath[p] == nil {
+			byPath[p] = make(map[string][]*Route)
+		}
+		byPath[p][rt.Method] = append(byPath[p][rt.Method], rt)
+	}
+
+	ops := make(map[operationKey]*boundOperation)
+	var errs []error
+
+	if doc.Components != nil {
+		for name, s := range doc.Components.Schemas {
+			if err := v.check(s, 0); err != nil {
+				errs = append(errs, fmt.Errorf("router: schema %s, %w", name, err))
+			}
+		}
+	}
+
+	for p, item := range doc.Paths {
+		for _, m := range specMethods {
+			op := item.Operation(m)
+			if op == nil {
+				continue
+			}
+
+			bound, err := v.bind(item, op)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("router: %s %s, %w", m, p, err))
+				continue
+			}
+
+			var routes []*Route
+			routes = append(routes, byPath[p][m]...)
+			routes = append(routes, byPath[p][MethodAll]...)
+			if len(routes) == 0 {
+				errs = append(errs, fmt.Errorf("router: no route for %s %s", m, p))
+				continue
+			}
+
+			for _, rt := range routes {
+				key := operationKey{rt, m}
+				if _, ok := ops[key]; !ok {
+					ops[key] = bound
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return func(next RouteHandler) RouteHandler {
+		return RouteHandlerFunc(func(w ResponseWriter, r *Request) {
+			op := ops[operationKey{r.Route(), r.Method}]
+			if op == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := v.validate(op, r); err != nil {
+				if _, ok := err.(*RequestValidationError); !ok || r.problems {
+					Error(w, r, err)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+type specValidator struct {
+	doc      *OpenAPI
+	patterns sync.Map
+}
+
+func (v *specValidator) bind(item *PathItem, op *Operation) (*boundOperation, error) {
+	bound := &boundOperation{body: op.RequestBody}
+
+	seen := make(map[string]int)
+	for _, list := range [][]*Parameter{item.Parameters, op.Parameters} {
+		for _, p := range list {
+			p, err := v.parameter(p)
+			if err != nil {
+				return nil, err
+			}
+			if err := v.check(p.Schema, 0); err != nil {
+				return nil, err
+			}
+
+			key := p.In + " " + p.Name
+			if i, ok := seen[key]; ok {
+				bound.params[i] = p
+				continue
+			}
+			seen[key] = len(bound.params)
+			bound.params = append(bound.params, p)
+		}
+	}
+
+	if op.RequestBody != nil {
+		for _, mt := range op.RequestBody.Content {
+			if err := v.check(mt.Schema, 0); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return bound, nil
+}
+
+func (v *specValidator) parameter(p *Parameter) (*Parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+
+	name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	if ok && v.doc.Components != nil && v.doc.Components.Parameters[name] != nil {
+		return v.doc.Components.Parameters[name], nil
+	}
+	return nil, fmt.Errorf("unresolvable reference %q", p.Ref)
+}
+
+func (v *specValidator) resolve(s *Schema) *Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		if !ok || v.doc.Components == nil {
+			return nil
+		}
+		s = v.doc.Components.Schemas[name]
+	}
+	return s
+}
+
+// check verifies that every reference in the schema can be resolved.
+func (v *specValidator) check(s *Schema, depth int) error {
+	if s == nil || depth > 32 {
+		return nil
+	}
+	if s.Ref != "" {
+		if v.resolve(s) == nil {
+			return fmt.Errorf("unresolvable reference %q", s.Ref)
+		}
+		return nil
+	}
+	if s.Pattern != "" {
+		if _, err := v.pattern(s.Pattern); err != nil {
+			return err
+		}
+	}
+
+	children := []*Schema{s.Items, s.AdditionalProperties, s.Not}
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	children = append(children, s.AllOf...)
+	children = append(children, s.AnyOf...)
+	children = append(children, s.OneOf...)
+
+	for _, c := range children {
+		if err := v.check(c, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *specValidator) pattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns.Store(expr, re)
+	return re, nil
+}
+
+// validate returns *RequestValidationError when the request disagrees with
+// the operation, or the failure on reading the request body.
+func (v *specValidator) validate(op *boundOperation, r *Request) error {
+	var vs violations
+
+	for _, p := range op.params {
+		vs.in, vs.name = p.In, p.Name
+
+		var values []string
+		switch p.In {
+		case "path":
+			if value, ok := r.Param(p.Name); ok {
+				values = []string{value}
+			}
+		case "query":
+			values = r.URL.Query()[p.Name]
+		case "header":
+			values = r.Header.Values(p.Name)
+		case "cookie":
+			if c, err := r.Cookie(p.Name); err == nil {
+				values = []string{c.Value}
+			}
+		}
+
+		if len(values) == 0 {
+			if p.Required {
+				vs.add("", "is required")
+			}
+			continue
+		}
+
+		v.value(p.Schema, v.coerce(p.Schema, values), "", &vs)
+	}
+
+	if op.body != nil {
+		vs.in, vs.name = "body", ""
+		if err := v.body(op.body, r, &vs); err != nil {
+			return err
+		}
+	}
+
+	if len(vs.list) == 0 {
+		return nil
+	}
+	return &RequestValidationError{Message: "request validation failed", Violations: vs.list}
+}
+
+func (v *specValidator) body(rb *RequestBody, r *Request, vs *violations) error {
+	var raw []byte
+	if r.Body != nil {
+		if err := r.BufferBody(defaultBodyBufferSize); err != nil {
+			return err
+		}
+		var err error
+		if raw, err = readBody(r); err != nil {
+			return err
+		}
+		r.RewindBody()
+	}
+
+	if len(raw) == 0 {
+		if rb.Required {
+			vs.add("", "is required")
+		}
+		return nil
+	}
+
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		ct = "application/json"
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		vs.add("", "has malformed content type %q", ct)
+		return nil
+	}
+
+	media := matchMediaType(rb.Content, mt)
+	if media == nil {
+		vs.add("", "has unsupported content type %q", mt)
+		return nil
+	}
+
+	if media.Schema == nil || !isJSONMediaType(mt) {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		vs.add("", "is not valid JSON")
+		return nil
+	}
+	v.value(media.Schema, value, "", vs)
+	return nil
+}
+
+func matchMediaType(content map[string]*MediaType, mt string) *MediaType {
+	if media, ok := content[mt]; ok {
+		return media
+	}
+	if i := strings.IndexByte(mt, '/'); i > 0 {
+		if media, ok := content[mt[:i]+"/*"]; ok {
+			return media
+		}
+	}
+	return content["*/*"]
+}
+
+func isJSONMediaType(mt string) bool {
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// coerce converts raw parameter values into JSON like values,
+// accordingly to the types allowed by the schema.
+func (v *specValidator) coerce(s *Schema, values []string) any {
+	s = v.resolve(s)
+	if s == nil {
+		return values[0]
+	}
+
+	if s.Type.Has("array") {
+		items := make([]any, len(values))
+		for i, value := range values {
+			items[i] = v.coerce(s.Items, []string{value})
+		}
+		return items
+	}
+
+	raw := values[0]
+	switch {
+	case s.Type.Has("integer"), s.Type.Has("number"):
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return n
+		}
+	case s.Type.Has("boolean"):
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+type violations struct {
+	in   string
+	name string
+	list []Violation
+}
+
+func (vs *violations) add(path, format string, args ...any) {
+	vs.list = append(vs.list, Violation{
+		In:      vs.in,
+		Name:    vs.name,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return ""
+}
+
+func (v *specValidator) value(s *Schema, value any, path string, vs *violations) {
+	s = v.resolve(s)
+	if s == nil {
+		return
+	}
+
+	if len(s.Type) > 0 {
+		typ := jsonType(value)
+		if !s.Type.Has(typ) && !(typ == "integer" && s.Type.Has("number")) {
+			vs.add(path, "must be of type %s", strings.Join(s.Type, " or "))
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		vs.add(path, "must be one of %v", s.Enum)
+	}
+
+	switch value := value.(type) {
+	case float64:
+		v.number(s, value, path, vs)
+	case string:
+		v.string(s, value, path, vs)
+	case []any:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			vs.add(path, "must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(value) > *s.MaxItems {
+			vs.add(path, "must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range value {
+			v.value(s.Items, item, path+"/"+strconv.Itoa(i), vs)
+		}
+	case map[string]any:
+		v.object(s, value, path, vs)
+	}
+
+	for _, sub := range s.AllOf {
+		v.value(sub, value, path, vs)
+	}
+	if len(s.AnyOf) > 0 && v.matches(s.AnyOf, value) == 0 {
+		vs.add(path, "must match at least one schema")
+	}
+	if len(s.OneOf) > 0 && v.matches(s.OneOf, value) != 1 {
+		vs.add(path, "must match exactly one schema")
+	}
+	if s.Not != nil && v.matches([]*Schema{s.Not}, value) == 1 {
+		vs.add(path, "must not match the schema")
+	}
+}
+
+func (v *specValidator) matches(schemas []*Schema, value any) int {
+	n := 0
+	for _, sub := range schemas {
+		var probe violations
+		v.value(sub, value, "", &probe)
+		if len(probe.list) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (v *specValidator) number(s *Schema, n float64, path string, vs *violations) {
+	if s.Minimum != nil && n < *s.Minimum {
+		vs.add(path, "must be greater than or equal to %v", *s.Minimum)
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		vs.add(path, "must be less than or equal to %v", *s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum {
+		vs.add(path, "must be greater than %v", *s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil && n >= *s.ExclusiveMaximum {
+		vs.add(path, "must be less than %v", *s.ExclusiveMaximum)
+	}
+}
+
+var uuidRegExp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func (v *specValidator) string(s *Schema, str string, path string, vs *violations) {
+	n := utf8.RuneCountInString(str)
+	if s.MinLength != nil && n < *s.MinLength {
+		vs.add(path, "must have at least %d characters", *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		vs.add(path, "must have at most %d characters", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		if re, err := v.pattern(s.Pattern); err == nil && !re.MatchString(str) {
+			vs.add(path, "must match pattern %q", s.Pattern)
+		}
+	}
+
+	var valid bool
+	switch s.Format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, str)
+		valid = err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, str)
+		valid = err == nil
+	case "email":
+		_, err := mail.ParseAddress(str)
+		valid = err == nil
+	case "uuid":
+		valid = uuidRegExp.MatchString(str)
+	default:
+		return
+	}
+	if !valid {
+		vs.add(path, "must be a valid %s", s.Format)
+	}
+}
+
+func (v *specValidator) object(s *Schema, obj map[string]any, path string, vs *violations) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			vs.add(path+"/"+name, "is required")
+		}
+	}
+
+	for name, value := range obj {
+		if prop, ok := s.Properties[name]; ok {
+			v.value(prop, value, path+"/"+name, vs)
+			continue
+		}
+		switch extra := s.AdditionalProperties; {
+		case extra == nil:
+		case extra.Not != nil && reflect.DeepEqual(*extra.Not, Schema{}):
+			vs.add(path+"/"+name, "is not allowed")
+		default:
+			v.value(extra, value, path+"/"+name, vs)
+		}
+	}
+}
+
+func inEnum(enum []any, value any) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
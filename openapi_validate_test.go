@@ -0,0 +1,183 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const petsSpec = `{
+	"openapi": "3.1.0",
+	"info": {"title": "Pets", "version": "1.0"},
+	"paths": {
+		"/pets": {
+			"get": {
+				"parameters": [
+					{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}},
+					{"$ref": "#/components/parameters/Version"}
+				],
+				"responses": {"200": {"description": "OK"}}
+			},
+			"post": {
+				"requestBody": {
+					"required": true,
+					"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+				},
+				"responses": {"201": {"description": "Created"}}
+			}
+		},
+		"/pets/{id}": {
+			"parameters": [
+				{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}
+			],
+			"get": {"responses": {"200": {"description": "OK"}}}
+		}
+	},
+	"components": {
+		"parameters": {
+			"Version": {"name": "X-Version", "in": "header", "required": true, "schema": {"type": "string", "enum": ["1", "2"]}}
+		},
+		"schemas": {
+			"Pet": {
+				"type": "object",
+				"required": ["name"],
+				"additionalProperties": false,
+				"properties": {
+					"name": {"type": "string", "minLength": 1},
+					"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+				}
+			}
+		}
+	}
+}`
+
+func TestValidateRequests(t *testing.T) {
+
+	doc, err := LoadOpenAPI(strings.NewReader(petsSpec))
+	assertNoError(t, err)
+
+	handler := &MockRouterHandler{
+		OnHandleFunc: func(w ResponseWriter, r *Request) {
+		},
+	}
+
+	router := NewRouter()
+	router.Get("/pets", handler)
+	router.Post("/pets", handler)
+	router.Use("/pets/{id}", handler)
+	router.Get("/health", handler)
+
+	mw, err := ValidateRequests(router, doc)
+	assertNoError(t, err)
+	router.Wrap(mw)
+
+	cases := []struct {
+		method     string
+		path       string
+		header     string
+		body       string
+		status     int
+		violations []string
+	}{
+		{http.MethodGet, "/pets?limit=10", "2", "", http.StatusOK, nil},
+		{http.MethodGet, "/pets?limit=0", "2", "", http.StatusBadRequest, []string{"query limit: must be greater than or equal to 1"}},
+		{http.MethodGet, "/pets?limit=a", "", "", http.StatusBadRequest, []string{"query limit: must be of type integer", "header X-Version: is required"}},
+		{http.MethodGet, "/pets", "3", "", http.StatusBadRequest, []string{"header X-Version: must be one of [1 2]"}},
+		{http.MethodPost, "/pets", "", `{"name": "Rex", "tags": ["dog"]}`, http.StatusOK, nil},
+		{http.MethodPost, "/pets", "", "", http.StatusBadRequest, []string{"body: is required"}},
+		{http.MethodPost, "/pets", "", `{"name": "", "age": 3, "tags": ["a", "b", 1]}`, http.StatusBadRequest, []string{
+			"body /name: must have at least 1 characters",
+			"body /age: is not allowed",
+			"body /tags: must have at most 2 items",
+			"body /tags/2: must be of type string",
+		}},
+		{http.MethodGet, "/pets/12", "", "", http.StatusOK, nil},
+		{http.MethodGet, "/pets/rex", "", "", http.StatusBadRequest, []string{"path id: must be of type integer"}},
+		{http.MethodGet, "/health", "", "", http.StatusOK, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.method+" "+c.path, func(t *testing.T) {
+			request, _ := http.NewRequest(c.method, newDummyURI(c.path), strings.NewReader(c.body))
+			if c.header != "" {
+				request.Header.Set("X-Version", c.header)
+			}
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assertStatus(t, response, c.status)
+
+			if c.violations == nil {
+				return
+			}
+
+			var got RequestValidationError
+			if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+				t.Fatalf("cannot decode violations, %v", err)
+			}
+
+			var msgs []string
+			for _, v := range got.Violations {
+				msgs = append(msgs, v.String())
+			}
+			if len(msgs) != len(c.violations) {
+				t.Fatalf("got violations %q, but want %q", msgs, c.violations)
+			}
+			assertSameStrings(t, msgs, c.violations)
+		})
+	}
+
+	t.Run("keeps the body for the handler", func(t *testing.T) {
+		var got struct{ Name string }
+		handler.OnHandleFunc = func(w ResponseWriter, r *Request) {
+			r.ParseBodyInto(&got)
+		}
+
+		request, _ := http.NewRequest(http.MethodPost, newDummyURI("/pets"), strings.NewReader(`{"name": "Rex"}`))
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		if got.Name != "Rex" {
+			t.Errorf("got body %+v, but want name Rex", got)
+		}
+	})
+}
+
+func TestValidateRequestsBinding(t *testing.T) {
+
+	doc, err := LoadOpenAPI(strings.NewReader(petsSpec))
+	assertNoError(t, err)
+
+	router := NewRouter()
+	router.Get("/pets", dummyHandler)
+
+	_, err = ValidateRequests(router, doc)
+	if err == nil {
+		t.Fatal("expected error for operations without route")
+	}
+	if !strings.Contains(err.Error(), "no route for POST /pets") {
+		t.Errorf("got error %q, but want it to name POST /pets", err)
+	}
+}
+
+func TestLoadOpenAPI(t *testing.T) {
+
+	t.Run("rejects other versions", func(t *testing.T) {
+		_, err := LoadOpenAPI(strings.NewReader(`{"swagger": "2.0"}`))
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("reads type lists", func(t *testing.T) {
+		doc, err := LoadOpenAPI(strings.NewReader(`{"openapi": "3.1.0", "components": {"schemas": {"A": {"type": ["string", "null"]}}}}`))
+		assertNoError(t, err)
+
+		s := doc.Components.Schemas["A"]
+		if !s.Type.Has("string") || !s.Type.Has("null") {
+			t.Errorf("got type %v, but want string and null", s.Type)
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package router
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Holds the params recognized from the request path,
+// by their names in the registered pattern.
+type Params map[string]string
+
+// pathParams holds the params matched by the router in a slice, which
+// is reused between requests, so no map is made for each one of them.
+// Params maps are built from it on demand.
+type pathParams []pathParam
+
+type pathParam struct {
+	name, value string
+}
+
+func (ps pathParams) get(name string) (string, bool) {
+	for _, p := range ps {
+		if p.name == name {
+			return p.value, true
+		}
+	}
+	return "", false
+}
+
+func (ps pathParams) toParams() Params {
+	params := make(Params, len(ps))
+	for _, p := range ps {
+		params[p.name] = p.value
+	}
+	return params
+}
+
+var (
+	ErrMissingParam = errors.New("router: missing param")
+	ErrInvalidUUID  = errors.New("router: invalid UUID")
+)
+
+// ParamError records a failure on getting a param as a typed value.
+type ParamError struct {
+	Name  string // the param name
+	Value string // the param raw value
+	Type  string // the type the param was asked as
+	Err   error  // the reason of the failure
+}
+
+func (e *ParamError) Error() string {
+	if errors.Is(e.Err, ErrMissingParam) {
+		return fmt.Sprintf("router: param %q is missing", e.Name)
+	}
+	return fmt.Sprintf("router: param %q has value %q, that is not a valid %s", e.Name, e.Value, e.Type)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns HTTP 400.
+func (e *ParamError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// Get returns the value of the param and whether it exists.
+func (p Params) Get(name string) (string, bool) {
+	v, ok := p[name]
+	return v, ok
+}
+
+func (p Params) parse(name, typ string, parse func(string) error) error {
+	v, ok := p[name]
+	if !ok {
+		return &ParamError{Name: name, Type: typ, Err: ErrMissingParam}
+	}
+	if err := parse(v); err != nil {
+		return &ParamError{Name: name, Value: v, Type: typ, Err: numError(err)}
+	}
+	return nil
+}
+
+// Int returns the param as an int.
+func (p Params) Int(name string) (v int, err error) {
+	err = p.parse(name, "int", func(s string) (err error) {
+		v, err = strconv.Atoi(s)
+		return
+	})
+	return
+}
+
+// Int64 returns the param as an int64.
+func (p Params) Int64(name string) (v int64, err error) {
+	err = p.parse(name, "int64", func(s string) (err error) {
+		v, err = strconv.ParseInt(s, 10, 64)
+		return
+	})
+	return
+}
+
+// Uint returns the param as an uint.
+func (p Params) Uint(name string) (v uint, err error) {
+	err = p.parse(name, "uint", func(s string) error {
+		n, err := strconv.ParseUint(s, 10, strconv.IntSize)
+		v = uint(n)
+		return err
+	})
+	return
+}
+
+// Bool returns the param as a bool, accepting the
+// same values of strconv.ParseBool.
+func (p Params) Bool(name string) (v bool, err error) {
+	err = p.parse(name, "bool", func(s string) (err error) {
+		v, err = strconv.ParseBool(s)
+		return
+	})
+	return
+}
+
+// Float returns the param as a float64.
+func (p Params) Float(name string) (v float64, err error) {
+	err = p.parse(name, "float", func(s string) (err error) {
+		v, err = strconv.ParseFloat(s, 64)
+		return
+	})
+	return
+}
+
+// UUID returns the param as an UUID, that must be
+// in the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func (p Params) UUID(name string) (v UUID, err error) {
+	err = p.parse(name, "UUID", func(s string) (err error) {
+		v, err = ParseUUID(s)
+		return
+	})
+	return
+}
+
+// Time returns the param as a time.Time parsed with the given layout.
+func (p Params) Time(name, layout string) (v time.Time, err error) {
+	err = p.parse(name, "time", func(s string) (err error) {
+		v, err = time.Parse(layout, s)
+		return
+	})
+	return
+}
+
+// UUID is a 128 bits universally unique identifier.
+type UUID [16]byte
+
+// ParseUUID parses an UUID in the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, ErrInvalidUUID
+	}
+
+	src := []byte(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	if _, err := hex.Decode(u[:], src); err != nil {
+		return u, ErrInvalidUUID
+	}
+	return u, nil
+}
+
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *UUID) UnmarshalText(text []byte) (err error) {
+	*u, err = ParseUUID(string(text))
+	return
+}
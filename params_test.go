@@ -0,0 +1,106 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParams(t *testing.T) {
+
+	params := Params{
+		"id":    "42",
+		"neg":   "-7",
+		"name":  "rex",
+		"ok":    "true",
+		"price": "3.14",
+		"uuid":  "d033fdc6-dbd2-427c-b18c-a41aa6449d75",
+		"day":   "2024-03-01",
+	}
+
+	t.Run("gets raw values", func(t *testing.T) {
+		if v, ok := params.Get("name"); !ok || v != "rex" {
+			t.Errorf("got %q %v, but want \"rex\" true", v, ok)
+		}
+		if _, ok := params.Get("missing"); ok {
+			t.Error("expected missing param")
+		}
+	})
+
+	t.Run("gets typed values", func(t *testing.T) {
+		i, err := params.Int("id")
+		assertNoError(t, err)
+		if i != 42 {
+			t.Errorf("got int %d, but want 42", i)
+		}
+
+		i64, err := params.Int64("neg")
+		assertNoError(t, err)
+		if i64 != -7 {
+			t.Errorf("got int64 %d, but want -7", i64)
+		}
+
+		u, err := params.Uint("id")
+		assertNoError(t, err)
+		if u != 42 {
+			t.Errorf("got uint %d, but want 42", u)
+		}
+
+		b, err := params.Bool("ok")
+		assertNoError(t, err)
+		if !b {
+			t.Error("got bool false, but want true")
+		}
+
+		f, err := params.Float("price")
+		assertNoError(t, err)
+		if f != 3.14 {
+			t.Errorf("got float %f, but want 3.14", f)
+		}
+
+		id, err := params.UUID("uuid")
+		assertNoError(t, err)
+		if id.String() != params["uuid"] {
+			t.Errorf("got UUID %s, but want %s", id, params["uuid"])
+		}
+
+		day, err := params.Time("day", time.DateOnly)
+		assertNoError(t, err)
+		if !day.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("got time %v, but want 2024-03-01", day)
+		}
+	})
+
+	t.Run("returns param errors", func(t *testing.T) {
+		cases := []struct {
+			get  func() error
+			name string
+			err  error
+		}{
+			{func() error { _, err := params.Int("name"); return err }, "name", strconv.ErrSyntax},
+			{func() error { _, err := params.Uint("neg"); return err }, "neg", strconv.ErrSyntax},
+			{func() error { _, err := params.UUID("id"); return err }, "id", ErrInvalidUUID},
+			{func() error { _, err := params.Bool("missing"); return err }, "missing", ErrMissingParam},
+		}
+
+		for _, c := range cases {
+			err := c.get()
+
+			var pe *ParamError
+			if !errors.As(err, &pe) {
+				t.Fatalf("got error %v, but want a *ParamError", err)
+			}
+			if pe.Name != c.name {
+				t.Errorf("got param name %q, but want %q", pe.Name, c.name)
+			}
+			if !errors.Is(err, c.err) {
+				t.Errorf("got error %v, but want it to wrap %v", err, c.err)
+			}
+			if pe.StatusCode() != http.StatusBadRequest {
+				t.Errorf("got status %d, but want %d", pe.StatusCode(), http.StatusBadRequest)
+			}
+		}
+	})
+}
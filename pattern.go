@@ -0,0 +1,308 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var ErrUnknownRoute = errors.New("router: unknown route")
+
+// PatternError records a malformed pattern, pointing to the column,
+// counted from 1, where the problem was found.
+type PatternError struct {
+	Pattern string
+	Column  int
+	Reason  string
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("router: invalid pattern %q: %s at column %d", e.Pattern, e.Reason, e.Column)
+}
+
+// ValidatePattern reports whether the pattern can be registered,
+// giving a *PatternError when it can't.
+func ValidatePattern(pattern string) error {
+	if pattern == "" {
+		return &PatternError{Pattern: pattern, Column: 1, Reason: "empty pattern"}
+	}
+	_, _, _, err := parsePattern(pattern, MethodAll)
+	return err
+}
+
+// parsePattern takes the pattern syntax of the standard ServeMux, since
+// Go 1.22, returning the pattern as the router holds it, its method and
+// its compiled form.
+//
+// The pattern can start with a method, like "GET /items/{id}", which
+// must agree with the registering method, unless it's MethodAll. A
+// trailing {$} is dropped, since patterns ending with a slash already
+// match only the exact path. A last wildcard ending with ..., like
+// {path...}, matches the rest of the path, see compilePattern.
+func parsePattern(pattern, method string) (string, string, *compiledPattern, error) {
+	orig := pattern
+	fail := func(col int, reason string) (string, string, *compiledPattern, error) {
+		return "", "", nil, &PatternError{Pattern: orig, Column: col, Reason: reason}
+	}
+
+	// A method has no slash, unlike paths and hosts with paths.
+	if m, rest, ok := strings.Cut(pattern, " "); ok && !strings.Contains(m, "/") {
+		rest = strings.TrimLeft(rest, " \t")
+		if m == "" {
+			return fail(1, "missing method")
+		}
+		if rest == "" {
+			return fail(len(orig)+1, "missing path")
+		}
+		if method != MethodAll && m != method {
+			return fail(1, "method "+m+" conflicts with "+method)
+		}
+		method, pattern = m, rest
+	}
+	offset := len(orig) - len(pattern)
+
+	if i := strings.Index(pattern, "{$}"); i >= 0 {
+		if i != len(pattern)-3 || i == 0 || pattern[i-1] != '/' {
+			return fail(offset+i+1, "{$} must end the pattern, after a slash")
+		}
+		pattern = pattern[:i]
+	}
+
+	cp, err := compilePattern(pattern)
+	if err != nil {
+		err.Pattern = orig
+		err.Column += offset
+		return "", "", nil, err
+	}
+
+	return pattern, method, cp, nil
+}
+
+// A patternPart is a piece of a compiled pattern, either
+// literal text or a wildcard, named by param.
+type patternPart struct {
+	literal string
+	param   string
+	rest    bool // the wildcard matches the rest of the path
+}
+
+// A compiledPattern is a pattern parsed once, at registration, into
+// its parts, which are then used for matching, building URLs and
+// introspection.
+type compiledPattern struct {
+	parts  []patternPart
+	params []string
+	// Holds the length of the literal text, the longer the
+	// more specific the pattern is.
+	literals int
+}
+
+// compilePattern parses the pattern, as given by parsePattern. A
+// wildcard, like {id}, starts a path segment, and may be followed by
+// literal text, like {id}.json. Anything else is literal, matched as
+// written. Params are named like Go identifiers, and only a last
+// wildcard may match the rest of the path, like {path...}.
+func compilePattern(pattern string) (*compiledPattern, *PatternError) {
+	cp := &compiledPattern{}
+	fail := func(i int, reason string) (*compiledPattern, *PatternError) {
+		return nil, &PatternError{Pattern: pattern, Column: i + 1, Reason: reason}
+	}
+
+	lit := 0 // start of the pending literal
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '}':
+			return fail(i, "unexpected }")
+		case '{':
+		default:
+			continue
+		}
+
+		if i == 0 || pattern[i-1] != '/' {
+			return fail(i, "wildcard must start a path segment")
+		}
+		end := strings.IndexAny(pattern[i+1:], "{}/")
+		if end < 0 || pattern[i+1+end] != '}' {
+			return fail(i, "unclosed wildcard")
+		}
+		end += i + 1
+
+		name := pattern[i+1 : end]
+		part := patternPart{param: name}
+		if rest, ok := strings.CutSuffix(name, "..."); ok {
+			if end != len(pattern)-1 {
+				return fail(i, "{"+name+"} must be the last segment")
+			}
+			part = patternPart{param: rest, rest: true}
+		}
+		if part.param == "" {
+			return fail(i+1, "missing param name")
+		}
+		if j := invalidNameIndex(part.param); j >= 0 {
+			return fail(i+1+j, fmt.Sprintf("invalid param name %q", part.param))
+		}
+		if slices.Contains(cp.params, part.param) {
+			return fail(i+1, fmt.Sprintf("duplicate param name %q", part.param))
+		}
+
+		if lit < i {
+			cp.parts = append(cp.parts, patternPart{literal: pattern[lit:i]})
+			cp.literals += i - lit
+		}
+		cp.parts = append(cp.parts, part)
+		cp.params = append(cp.params, part.param)
+		i, lit = end, end+1
+	}
+	if lit < len(pattern) {
+		cp.parts = append(cp.parts, patternPart{literal: pattern[lit:]})
+		cp.literals += len(pattern) - lit
+	}
+
+	return cp, nil
+}
+
+// Returns the index of the first byte keeping the name from being a Go
+// identifier, or -1 if it's one. Only ASCII letters are taken, as
+// regexp group names require.
+func invalidNameIndex(name string) int {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return i
+		}
+	}
+	return -1
+}
+
+// regexp returns the expression matching the pattern, with a named
+// group for each param.
+func (cp *compiledPattern) regexp() string {
+	var b strings.Builder
+	b.WriteByte('^')
+	for _, part := range cp.parts {
+		switch {
+		case part.param == "":
+			b.WriteString(strings.ReplaceAll(regexp.QuoteMeta(part.literal), "/", `\/`))
+		case part.rest:
+			b.WriteString("(?P<" + part.param + ">.*)")
+		default:
+			b.WriteString("(?P<" + part.param + `>[^\/]+)`)
+		}
+	}
+	b.WriteByte('$')
+	return b.String()
+}
+
+// Reports whether the pattern has no params.
+func (cp *compiledPattern) static() bool {
+	return len(cp.params) == 0
+}
+
+// Reports whether the pattern ends with a wildcard
+// matching the rest of the path.
+func (cp *compiledPattern) isRest() bool {
+	return len(cp.parts) > 0 && cp.parts[len(cp.parts)-1].rest
+}
+
+// path returns the pattern without its host, if any, and with its
+// params replaced by the result of fn.
+func (cp *compiledPattern) path(fn func(part patternPart) (string, error)) (string, error) {
+	var b strings.Builder
+	for i, part := range cp.parts {
+		if part.param == "" {
+			lit := part.literal
+			if i == 0 && lit[0] != '/' {
+				if j := strings.IndexByte(lit, '/'); j >= 0 {
+					lit = lit[j:]
+				}
+			}
+			b.WriteString(lit)
+			continue
+		}
+		v, err := fn(part)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(v)
+	}
+	return b.String(), nil
+}
+
+// build returns the path of the pattern with the params replaced by
+// the escaped values. The value of a rest wildcard keeps its slashes.
+func (cp *compiledPattern) build(params Params) (string, error) {
+	return cp.path(func(part patternPart) (string, error) {
+		v, ok := params[part.param]
+		if !ok {
+			return "", &ParamError{Name: part.param, Err: ErrMissingParam}
+		}
+		if !part.rest {
+			return url.PathEscape(v), nil
+		}
+		segs := strings.Split(v, "/")
+		for i, seg := range segs {
+			segs[i] = url.PathEscape(seg)
+		}
+		return strings.Join(segs, "/"), nil
+	})
+}
+
+// docPath returns the path of the pattern as documented by OpenAPI,
+// without the host and the dots of rest wildcards.
+func (cp *compiledPattern) docPath() string {
+	p, _ := cp.path(func(part patternPart) (string, error) {
+		return "{" + part.param + "}", nil
+	})
+	return p
+}
+
+// setPathValues makes the params available through the
+// standard http.Request PathValue method.
+func (r *Request) setPathValues() {
+	for _, p := range r.pathParams {
+		r.Request.SetPathValue(p.name, p.value)
+	}
+}
+
+// compiled returns the parsed pattern of the route, parsing it for
+// routes not made by the router. Malformed patterns are taken
+// as literal.
+func (rt *Route) compiled() *compiledPattern {
+	if rt.pat != nil {
+		return rt.pat
+	}
+	if cp, err := compilePattern(rt.Pattern); err == nil {
+		return cp
+	}
+	return &compiledPattern{parts: []patternPart{{literal: rt.Pattern}}}
+}
+
+// Params returns the names of the route params, in the order they
+// appear in the pattern.
+func (rt *Route) Params() []string {
+	return slices.Clone(rt.compiled().params)
+}
+
+// URL returns the path of the route with its params replaced by the
+// given values, which are escaped. The host of host patterns and the
+// version prefix, if any, are left out.
+func (rt *Route) URL(params Params) (string, error) {
+	return rt.compiled().build(params)
+}
+
+// URL returns the path of the route with the given name, see
+// Route.URL. It fails with ErrUnknownRoute if there's no such route.
+func (ro *Router) URL(name string, params Params) (string, error) {
+	for _, rt := range ro.Routes() {
+		if rt.Name == name {
+			return rt.URL(params)
+		}
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownRoute, name)
+}
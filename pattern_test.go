@@ -0,0 +1,342 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func pathValueHandler(names ...string) RouteHandlerFunc {
+	return func(w ResponseWriter, r *Request) {
+		for i, name := range names {
+			if i > 0 {
+				w.Write([]byte(" "))
+			}
+			w.Write([]byte(r.PathValue(name)))
+		}
+	}
+}
+
+func TestServeMuxPatterns(t *testing.T) {
+	router := NewRouter()
+	router.Use("site.com/users/{name}/{rest...}", pathValueHandler("name", "rest"))
+	router.Use("GET /items/{id}", pathValueHandler("id"))
+	router.Post("POST /items/{id}", namedHandler("posted"))
+	router.Use("/files/{path...}", pathValueHandler("path"))
+	router.Use("/files/readme", namedHandler("readme"))
+	router.Use("/docs/{$}", namedHandler("docs"))
+
+	cases := []struct {
+		name   string
+		method string
+		url    string
+		status int
+		body   string
+	}{
+		{"method prefix", http.MethodGet, "/items/3", http.StatusOK, "3"},
+		{"other method", http.MethodPut, "/items/3", http.StatusNotFound, ""},
+		{"method prefix of method registration", http.MethodPost, "/items/3", http.StatusOK, "posted"},
+		{"rest wildcard", http.MethodGet, "/files/a/b/c.txt", http.StatusOK, "a/b/c.txt"},
+		{"empty rest wildcard", http.MethodGet, "/files/", http.StatusOK, ""},
+		{"literal before rest wildcard", http.MethodGet, "/files/readme", http.StatusOK, "readme"},
+		{"exact match anchor", http.MethodGet, "/docs/", http.StatusOK, "docs"},
+		{"exact match anchor only", http.MethodGet, "/docs/intro", http.StatusNotFound, ""},
+		{"host", http.MethodGet, "http://site.com/users/ann/posts/1", http.StatusOK, "ann posts/1"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request := httptest.NewRequest(c.method, c.url, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assertStatus(t, response, c.status)
+			assertBody(t, response, c.body)
+		})
+	}
+
+	t.Run("registers the method of the pattern", func(t *testing.T) {
+		for _, rt := range router.Routes() {
+			if rt.Pattern == "/items/{id}" && rt.Method != MethodGet && rt.Method != MethodPost {
+				t.Errorf("got method %q, but want GET or POST", rt.Method)
+			}
+			if rt.Pattern == "/docs/{$}" {
+				t.Error("expected {$} dropped from the pattern")
+			}
+		}
+	})
+
+	t.Run("documents wildcards without dots", func(t *testing.T) {
+		doc := router.OpenAPI(OpenAPIInfo{})
+		item, ok := doc.Paths["/files/{path}"]
+		if !ok {
+			t.Fatal("expected /files/{path} documented")
+		}
+		if params := item.Get.Parameters; len(params) != 1 || params[0].Name != "path" {
+			t.Errorf("got params %v, but want path", params)
+		}
+	})
+
+	t.Run("panics on bad patterns", func(t *testing.T) {
+		cases := []struct {
+			pattern string
+			method  string
+		}{
+			{"POST /items", MethodGet},
+			{"/items/{$}/more", MethodAll},
+			{"/items{$}", MethodAll},
+			{"/files/{path...}/more", MethodAll},
+			{"GET ", MethodAll},
+		}
+
+		for _, c := range cases {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("expected panic on %q", c.pattern)
+					}
+				}()
+				NewRouter().register(c.pattern, dummyHandler, c.method)
+			}()
+		}
+	})
+}
+
+func TestServeMuxPrecedence(t *testing.T) {
+
+	t.Run("takes the most specific rest wildcard", func(t *testing.T) {
+		router := NewRouter()
+		router.Use("/files/{p...}", namedHandler("files"))
+		router.Use("/files/img/{p...}", namedHandler("img"))
+		router.Use("/files/{dir}/{p...}", namedHandler("dir"))
+
+		cases := map[string]string{
+			"/files/img/x":   "img",
+			"/files/doc/x":   "dir",
+			"/files/readme":  "files",
+			"/files/img/a/b": "img",
+		}
+
+		for url, want := range cases {
+			for i := 0; i < 20; i++ {
+				request := httptest.NewRequest(http.MethodGet, url, nil)
+				response := httptest.NewRecorder()
+
+				router.ServeHTTP(response, request)
+
+				assertBody(t, response, want)
+			}
+		}
+	})
+
+	t.Run("serves HEAD requests by GET routes", func(t *testing.T) {
+		router := NewRouter()
+		router.Use("GET /items/{id}", namedHandler("get"))
+		router.Use("HEAD /users", namedHandler("head"))
+		router.Get("/users", namedHandler("get"))
+
+		cases := []struct {
+			method string
+			url    string
+			status int
+			body   string
+		}{
+			{http.MethodHead, "/items/1", http.StatusOK, "get"},
+			{http.MethodHead, "/users", http.StatusOK, "head"},
+			{http.MethodGet, "/users", http.StatusOK, "get"},
+			{http.MethodPost, "/items/1", http.StatusNotFound, ""},
+		}
+
+		for _, c := range cases {
+			request := httptest.NewRequest(c.method, c.url, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assertStatus(t, response, c.status)
+			assertBody(t, response, c.body)
+		}
+	})
+}
+
+func TestCompilePattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		re      string
+		params  []string
+	}{
+		{"/users", `^\/users$`, nil},
+		{"/users/{id}", `^\/users\/(?P<id>[^\/]+)$`, []string{"id"}},
+		{"/users/{id}/posts/{post}", `^\/users\/(?P<id>[^\/]+)\/posts\/(?P<post>[^\/]+)$`, []string{"id", "post"}},
+		{"/files/{path...}", `^\/files\/(?P<path>.*)$`, []string{"path"}},
+		{"/files/{name}.json", `^\/files\/(?P<name>[^\/]+)\.json$`, []string{"name"}},
+		{"site.com/users/{id}", `^site\.com\/users\/(?P<id>[^\/]+)$`, []string{"id"}},
+		{"/price/$5", `^\/price\/\$5$`, nil},
+		{"/a+b", `^\/a\+b$`, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.pattern, func(t *testing.T) {
+			cp, err := compilePattern(c.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if got := cp.regexp(); got != c.re {
+				t.Errorf("got regexp %q, but want %q", got, c.re)
+			}
+			if !reflect.DeepEqual(cp.params, c.params) {
+				t.Errorf("got params %v, but want %v", cp.params, c.params)
+			}
+		})
+	}
+}
+
+func TestPatternErrors(t *testing.T) {
+	cases := []struct {
+		pattern string
+		column  int
+		reason  string
+	}{
+		{"/a/{id", 4, "unclosed wildcard"},
+		{"/a/{id/b}", 4, "unclosed wildcard"},
+		{"/x/{a b}", 6, `invalid param name "a b"`},
+		{"/x/{1a}", 5, `invalid param name "1a"`},
+		{"/x/{}", 5, "missing param name"},
+		{"/x/{...}", 5, "missing param name"},
+		{"/x/{id}/y/{id}", 12, `duplicate param name "id"`},
+		{"/x/a{id}", 5, "wildcard must start a path segment"},
+		{"/x/{a}{b}", 7, "wildcard must start a path segment"},
+		{"/x/a}", 5, "unexpected }"},
+		{"/files/{path...}/more", 8, "{path...} must be the last segment"},
+		{"GET /a/{id", 8, "unclosed wildcard"},
+		{"POST /items", 1, "method POST conflicts with GET"},
+		{"/items/{$}/more", 8, "{$} must end the pattern, after a slash"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.pattern, func(t *testing.T) {
+			method := MethodAll
+			if strings.HasPrefix(c.pattern, "POST") {
+				method = MethodGet
+			}
+			_, _, _, err := parsePattern(c.pattern, method)
+
+			var got *PatternError
+			if !errors.As(err, &got) {
+				t.Fatalf("got error %v, but want a *PatternError", err)
+			}
+			if got.Pattern != c.pattern || got.Column != c.column || got.Reason != c.reason {
+				t.Errorf("got %+v, but want column %d and reason %q", got, c.column, c.reason)
+			}
+		})
+	}
+
+	t.Run("validates patterns", func(t *testing.T) {
+		assertNoError(t, ValidatePattern("GET /users/{id}"))
+
+		err := ValidatePattern("/users/{id")
+		want := `router: invalid pattern "/users/{id": unclosed wildcard at column 8`
+		if err == nil || err.Error() != want {
+			t.Errorf("got error %v, but want %q", err, want)
+		}
+	})
+
+	t.Run("panics with the error on registration", func(t *testing.T) {
+		defer func() {
+			if _, ok := recover().(*PatternError); !ok {
+				t.Error("expected a *PatternError panic")
+			}
+		}()
+		NewRouter().Get("/a/{id", dummyHandler)
+	})
+
+	t.Run("matches literals as written", func(t *testing.T) {
+		router := NewRouter()
+		router.Get("/a+b", namedHandler("plus"))
+
+		for url, status := range map[string]int{"/a+b": http.StatusOK, "/aab": http.StatusNotFound} {
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, url, nil))
+			assertStatus(t, response, status)
+		}
+	})
+}
+
+func TestRouteURL(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/{id}/posts/{post}", dummyHandler, Name("getPost"))
+	router.Get("/files/{path...}", dummyHandler, Name("getFile"))
+	router.Get("site.com/pages/{page}", dummyHandler, Name("getPage"))
+
+	cases := []struct {
+		name   string
+		params Params
+		want   string
+	}{
+		{"getPost", Params{"id": "7", "post": "hello world"}, "/users/7/posts/hello%20world"},
+		{"getFile", Params{"path": "a/b c.txt"}, "/files/a/b%20c.txt"},
+		{"getPage", Params{"page": "about"}, "/pages/about"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := router.URL(c.name, c.params)
+
+			assertNoError(t, err)
+			if got != c.want {
+				t.Errorf("got %q, but want %q", got, c.want)
+			}
+		})
+	}
+
+	t.Run("escapes slashes of params", func(t *testing.T) {
+		got, err := router.URL("getPost", Params{"id": "a/b", "post": "1"})
+
+		assertNoError(t, err)
+		if want := "/users/a%2Fb/posts/1"; got != want {
+			t.Errorf("got %q, but want %q", got, want)
+		}
+	})
+
+	t.Run("fails on missing params", func(t *testing.T) {
+		_, err := router.URL("getPost", Params{"id": "7"})
+
+		if !errors.Is(err, ErrMissingParam) {
+			t.Errorf("got error %v, but want %v", err, ErrMissingParam)
+		}
+	})
+
+	t.Run("fails on unknown routes", func(t *testing.T) {
+		_, err := router.URL("unknown", nil)
+
+		if !errors.Is(err, ErrUnknownRoute) {
+			t.Errorf("got error %v, but want %v", err, ErrUnknownRoute)
+		}
+	})
+
+	t.Run("gives the route params", func(t *testing.T) {
+		for _, rt := range router.Routes() {
+			if rt.Name == "getPost" {
+				if got, want := rt.Params(), []string{"id", "post"}; !reflect.DeepEqual(got, want) {
+					t.Errorf("got params %v, but want %v", got, want)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkRegister(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		router := NewRouter()
+		for j := 0; j < 1000; j++ {
+			router.Get(fmt.Sprintf("/catalog%d/items/{id}/parts/{part}", j), dummyHandler)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Problem describes an error reply, as defined by RFC 9457, which is
+// written as application/problem+json. It's also an error, so handlers
+// can return it through ErrorHandlerFunc.
+type Problem struct {
+	Type     string // a URI identifying the problem type, about:blank when empty
+	Title    string // a short summary of the problem type
+	Status   int    // the HTTP status code
+	Detail   string // an explanation of this occurrence of the problem
+	Instance string // a URI identifying this occurrence of the problem
+	// Holds extra members, which are written along with the
+	// standard ones, without replacing them.
+	Extensions map[string]any
+}
+
+// NewProblem returns a problem of the given status code, titled
+// by its status text.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return "router: " + p.Title
+	}
+	return "router: " + p.Title + ", " + p.Detail
+}
+
+// StatusCode returns the problem status, or 500 when not set.
+func (p *Problem) StatusCode() int {
+	if p.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return p.Status
+}
+
+var problemMembers = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		if !problemMembers[k] {
+			m[k] = v
+		}
+	}
+
+	members := map[string]string{"type": p.Type, "title": p.Title, "detail": p.Detail, "instance": p.Instance}
+	for k, v := range members {
+		if v != "" {
+			m[k] = v
+		}
+	}
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+	return json.Marshal(m)
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	*p = Problem{}
+	members := map[string]any{"type": &p.Type, "title": &p.Title, "status": &p.Status, "detail": &p.Detail, "instance": &p.Instance}
+	for k, raw := range m {
+		if dst, ok := members[k]; ok {
+			// Members of wrong types are ignored, as RFC 9457 says.
+			json.Unmarshal(raw, dst)
+			continue
+		}
+
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any)
+		}
+		p.Extensions[k] = v
+	}
+	return nil
+}
+
+// ServeHTTP writes the problem, which makes it usable as a handler.
+func (p *Problem) ServeHTTP(w ResponseWriter, r *Request) {
+	WriteProblem(w, p)
+}
+
+// WriteProblem replies p as application/problem+json, with its status code.
+func WriteProblem(w ResponseWriter, p *Problem) error {
+	return render(w, p.StatusCode(), "application/problem+json", JSONEncoder{}, p)
+}
+
+// ProblemFor describes err as a problem. Problems are returned as they
+// are, other errors get the status code they carry, or 500 when none.
+// Details of server errors are left out, not to leak internals, and the
+// failures of validation and binding are listed in the errors member.
+func ProblemFor(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+
+	status := errorStatus(err)
+	if status >= 500 {
+		return NewProblem(status, "")
+	}
+	p = NewProblem(status, strings.TrimPrefix(err.Error(), "router: "))
+
+	var verrs ValidationErrors
+	var berrs BindErrors
+	var rverr *RequestValidationError
+
+	switch {
+	case errors.As(err, &verrs):
+		p.Extensions = map[string]any{"errors": verrs}
+	case errors.As(err, &berrs):
+		list := make([]map[string]string, len(berrs))
+		for i, e := range berrs {
+			list[i] = map[string]string{"field": e.Field, "source": e.Source, "name": e.Name, "message": e.Err.Error()}
+		}
+		p.Extensions = map[string]any{"errors": list}
+	case errors.As(err, &rverr):
+		p.Detail = rverr.Message
+		p.Extensions = map[string]any{"violations": rverr.Violations}
+	}
+	return p
+}
+
+// Error replies err with the status code it carries, or 500 when none.
+// Problems are always written as application/problem+json, as well as
+// any error when the router has Problems set, other errors are written
+// as plain text, hiding the details of server errors.
+func Error(w ResponseWriter, r *Request, err error) {
+	var p *Problem
+	if errors.As(err, &p) || r.problems {
+		WriteProblem(w, ProblemFor(err))
+		return
+	}
+
+	status := errorStatus(err)
+	msg := err.Error()
+	if status >= 500 {
+		msg = http.StatusText(status)
+	}
+	http.Error(w, msg, status)
+}
+
+// ErrorHandlerFunc is an adapter to allow the use of functions that
+// return errors as handlers. Returned errors are replied through Error,
+// so returning a *Problem writes it directly.
+type ErrorHandlerFunc func(ResponseWriter, *Request) error
+
+func (f ErrorHandlerFunc) ServeHTTP(w ResponseWriter, r *Request) {
+	if err := f(w, r); err != nil {
+		Error(w, r, err)
+	}
+}
+
+// replyStatus replies the bare status code, or a problem
+// describing it when the router has Problems set.
+func replyStatus(w ResponseWriter, r *Request, status int) {
+	if r.problems {
+		WriteProblem(w, NewProblem(status, ""))
+		return
+	}
+	w.WriteHeader(status)
+}
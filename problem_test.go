@@ -0,0 +1,168 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decodeProblem(t testing.TB, response *httptest.ResponseRecorder) *Problem {
+	t.Helper()
+
+	assertContentType(t, response, "application/problem+json")
+
+	var p Problem
+	if err := json.NewDecoder(response.Body).Decode(&p); err != nil {
+		t.Fatalf("cannot decode problem, %v", err)
+	}
+	return &p
+}
+
+func TestProblem(t *testing.T) {
+
+	t.Run("encodes members and extensions", func(t *testing.T) {
+		p := &Problem{
+			Type:       "https://example.com/probs/out-of-credit",
+			Title:      "You do not have enough credit.",
+			Status:     http.StatusForbidden,
+			Detail:     "Your current balance is 30, but that costs 50.",
+			Instance:   "/account/12345/msgs/abc",
+			Extensions: map[string]any{"balance": 30, "status": "ignored"},
+		}
+
+		raw, err := json.Marshal(p)
+		assertNoError(t, err)
+
+		want := `{"balance":30,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc","status":403,"title":"You do not have enough credit.","type":"https://example.com/probs/out-of-credit"}`
+		if string(raw) != want {
+			t.Errorf("got %s, but want %s", raw, want)
+		}
+
+		var got Problem
+		assertNoError(t, json.Unmarshal(raw, &got))
+		p.Extensions = map[string]any{"balance": float64(30)}
+		if !reflect.DeepEqual(&got, p) {
+			t.Errorf("got %+v, but want %+v", got, *p)
+		}
+	})
+
+	t.Run("writes itself", func(t *testing.T) {
+		response := httptest.NewRecorder()
+
+		assertNoError(t, WriteProblem(response, NewProblem(http.StatusConflict, "already exists")))
+
+		assertStatus(t, response, http.StatusConflict)
+		got := decodeProblem(t, response)
+		if got.Title != "Conflict" || got.Detail != "already exists" {
+			t.Errorf("got %+v, but want a conflict", got)
+		}
+	})
+}
+
+func TestProblemFor(t *testing.T) {
+
+	t.Run("keeps problems", func(t *testing.T) {
+		p := NewProblem(http.StatusTeapot, "")
+		if got := ProblemFor(p); got != p {
+			t.Errorf("got %+v, but want the same problem", got)
+		}
+	})
+
+	t.Run("describes status errors", func(t *testing.T) {
+		got := ProblemFor(&UnsupportedMediaTypeError{"text/csv"})
+		if got.Status != http.StatusUnsupportedMediaType || got.Detail != `unsupported media type "text/csv"` {
+			t.Errorf("got %+v, but want 415", got)
+		}
+	})
+
+	t.Run("lists validation errors", func(t *testing.T) {
+		verrs := ValidationErrors{{Field: "name", Rule: "required", Message: "is required"}}
+		got := ProblemFor(verrs)
+		if got.Status != http.StatusUnprocessableEntity || !reflect.DeepEqual(got.Extensions["errors"], verrs) {
+			t.Errorf("got %+v, but want 422 listing the errors", got)
+		}
+	})
+
+	t.Run("hides details of server errors", func(t *testing.T) {
+		got := ProblemFor(errors.New("database is down"))
+		if got.Status != http.StatusInternalServerError || got.Detail != "" {
+			t.Errorf("got %+v, but want 500 without detail", got)
+		}
+	})
+}
+
+func TestError(t *testing.T) {
+
+	t.Run("writes plain text by default", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/pets"), "")
+		response := httptest.NewRecorder()
+
+		Error(response, request, &BodyTooLargeError{Limit: 8})
+
+		assertStatus(t, response, http.StatusRequestEntityTooLarge)
+		assertBody(t, response, "router: request body is bigger than 8 bytes\n")
+	})
+
+	t.Run("writes problems returned by handlers", func(t *testing.T) {
+		router := NewRouter()
+		router.Get("/pets/{id}", ErrorHandlerFunc(func(w ResponseWriter, r *Request) error {
+			return &Problem{Type: "https://example.com/probs/gone", Title: "Gone", Status: http.StatusGone}
+		}))
+
+		request, _ := http.NewRequest(http.MethodGet, newDummyURI("/pets/1"), nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusGone)
+		if got := decodeProblem(t, response); got.Type != "https://example.com/probs/gone" {
+			t.Errorf("got %+v, but want the returned problem", got)
+		}
+	})
+
+	t.Run("writes problems when the router has Problems set", func(t *testing.T) {
+		router := NewRouter()
+		router.Problems = true
+		router.MaxBodySize = 4
+		router.Post("/pets", ErrorHandlerFunc(func(w ResponseWriter, r *Request) error {
+			var p pet
+			return r.ParseBodyInto(&p)
+		}))
+
+		cases := []struct {
+			method, path, contentType, body string
+			status                          int
+		}{
+			{http.MethodGet, "/unknown", "", "", http.StatusNotFound},
+			{http.MethodPost, "/pets", "", "12345", http.StatusRequestEntityTooLarge},
+			{http.MethodPost, "/pets", "application/x-unknown", "a", http.StatusUnsupportedMediaType},
+			{http.MethodPost, "/pets", "", "{", http.StatusBadRequest},
+		}
+
+		for _, c := range cases {
+			request, _ := http.NewRequest(c.method, newDummyURI(c.path), strings.NewReader(c.body))
+			request.Header.Set("Content-Type", c.contentType)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assertStatus(t, response, c.status)
+			if got := decodeProblem(t, response); got.Status != c.status {
+				t.Errorf("got problem status %d, but want %d", got.Status, c.status)
+			}
+		}
+
+		router.HandleMethodNotAllowed = true
+		request, _ := http.NewRequest(http.MethodDelete, newDummyURI("/pets"), nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusMethodNotAllowed)
+		decodeProblem(t, response)
+	})
+}
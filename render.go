@@ -0,0 +1,282 @@
+package router
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoder writes v into w, in some media type.
+type Encoder interface {
+	Encode(w io.Writer, v any) error
+}
+
+// An adapter to allow the use of functions as encoders.
+type EncoderFunc func(w io.Writer, v any) error
+
+func (f EncoderFunc) Encode(w io.Writer, v any) error {
+	return f(w, v)
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{
+		"application/json": JSONEncoder{},
+		"application/xml":  XMLEncoder{},
+		"text/xml":         XMLEncoder{},
+		"text/plain":       TextEncoder{},
+	}
+	// Holds the media types in the order they are offered by Negotiate.
+	encoderTypes = []string{"application/json", "application/xml", "text/xml", "text/plain"}
+)
+
+// RegisterEncoder records the encoder used by Negotiate for responses
+// of the given media type, like application/msgpack. It replaces any
+// encoder of the same media type, new media types are offered after
+// the ones already registered.
+func RegisterEncoder(mediaType string, e Encoder) {
+	if e == nil {
+		panic("router: nil encoder")
+	}
+
+	mediaType = strings.ToLower(mediaType)
+	if mt, _, err := mime.ParseMediaType(mediaType); err != nil || mt != mediaType || strings.Contains(mt, "*") {
+		panic("router: invalid media type " + mediaType)
+	}
+
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	if _, ok := encoders[mediaType]; !ok {
+		encoderTypes = append(encoderTypes, mediaType)
+	}
+	encoders[mediaType] = e
+}
+
+// JSONEncoder encodes values as JSON.
+type JSONEncoder struct {
+	// Holds the indentation of nested values, no indentation
+	// is made when empty.
+	Indent string
+}
+
+func (e JSONEncoder) Encode(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	if e.Indent != "" {
+		enc.SetIndent("", e.Indent)
+	}
+	return enc.Encode(v)
+}
+
+// XMLEncoder encodes values as XML, preceded by the XML header.
+type XMLEncoder struct{}
+
+func (XMLEncoder) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// TextEncoder encodes values as plain text. Strings and []byte are
+// written as they are, types implementing encoding.TextMarshaler are
+// marshaled, and anything else is formatted like fmt.Print does.
+type TextEncoder struct{}
+
+func (TextEncoder) Encode(w io.Writer, v any) error {
+	switch v := v.(type) {
+	case string:
+		_, err := io.WriteString(w, v)
+		return err
+	case []byte:
+		_, err := w.Write(v)
+		return err
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(text)
+		return err
+	default:
+		_, err := fmt.Fprint(w, v)
+		return err
+	}
+}
+
+// NotAcceptableError records a request whose Accept header
+// matches none of the media types that can be replied.
+type NotAcceptableError struct {
+	Accept string
+}
+
+func (e *NotAcceptableError) Error() string {
+	return fmt.Sprintf("router: no acceptable media type for %q", e.Accept)
+}
+
+// StatusCode returns HTTP 406.
+func (e *NotAcceptableError) StatusCode() int {
+	return http.StatusNotAcceptable
+}
+
+// render encodes v before writing anything, so encoding failures
+// can still be replied by the caller.
+func render(w ResponseWriter, code int, contentType string, e Encoder, v any) error {
+	var buf bytes.Buffer
+	if err := e.Encode(&buf, v); err != nil {
+		return err
+	}
+	return Blob(w, code, contentType, buf.Bytes())
+}
+
+// JSON replies v encoded as JSON with the given status code.
+func JSON(w ResponseWriter, code int, v any) error {
+	return render(w, code, "application/json; charset=utf-8", JSONEncoder{}, v)
+}
+
+// XML replies v encoded as XML with the given status code.
+func XML(w ResponseWriter, code int, v any) error {
+	return render(w, code, "application/xml; charset=utf-8", XMLEncoder{}, v)
+}
+
+// Text replies the plain text s with the given status code.
+func Text(w ResponseWriter, code int, s string) error {
+	return Blob(w, code, "text/plain; charset=utf-8", []byte(s))
+}
+
+// HTML replies the HTML document s with the given status code.
+func HTML(w ResponseWriter, code int, s string) error {
+	return Blob(w, code, "text/html; charset=utf-8", []byte(s))
+}
+
+// Blob replies data, of the given content type, with the given
+// status code.
+func Blob(w ResponseWriter, code int, contentType string, data []byte) error {
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(code)
+	_, err := w.Write(data)
+	return err
+}
+
+// NoContent replies HTTP 204 without body.
+func NoContent(w ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Negotiate replies v, with the given status code, encoded by the
+// registered encoder whose media type best fits the request Accept
+// header. JSON, XML and plain text are supported out of the box.
+//
+// When no encoder fits, it replies HTTP 406 and returns
+// *NotAcceptableError.
+func Negotiate(w ResponseWriter, r *Request, code int, v any) error {
+	encodersMu.RLock()
+	mt := NegotiateContentType(r.Header.Get("Accept"), encoderTypes)
+	e := encoders[mt]
+	encodersMu.RUnlock()
+
+	w.Header().Add("Vary", "Accept")
+
+	if e == nil {
+		err := &NotAcceptableError{r.Header.Get("Accept")}
+		http.Error(w, err.Error(), err.StatusCode())
+		return err
+	}
+
+	ct := mt
+	if strings.HasPrefix(mt, "text/") || isJSONMediaType(mt) || mt == "application/xml" || strings.HasSuffix(mt, "+xml") {
+		ct += "; charset=utf-8"
+	}
+	return render(w, code, ct, e, v)
+}
+
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// NegotiateContentType returns the offer that best fits the given Accept
+// header, taking its quality values into account, or an empty string when
+// none fits. Ties are given to the first offer. An empty Accept header
+// accepts any offer.
+func NegotiateContentType(accept string, offers []string) string {
+	best, _ := negotiate(accept, offers)
+	return best
+}
+
+// negotiate returns the offer that best fits the Accept header,
+// along with its quality value.
+func negotiate(accept string, offers []string) (string, float64) {
+	if len(offers) == 0 {
+		return "", 0
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], 1
+	}
+
+	ranges := parseAccept(accept)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		typ, subtype, _ := strings.Cut(strings.ToLower(offer), "/")
+
+		// The most specific range decides the offer quality.
+		q, specificity := 0.0, -1
+		for _, ar := range ranges {
+			s := 0
+			switch {
+			case ar.typ == typ && ar.subtype == subtype:
+				s = 2
+			case ar.typ == typ && ar.subtype == "*":
+				s = 1
+			case ar.typ == "*" && ar.subtype == "*":
+				s = 0
+			default:
+				continue
+			}
+			if s > specificity {
+				q, specificity = ar.q, s
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ
+}
+
+// parseAccept parses the media ranges of an Accept header,
+// skipping the malformed ones.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mt, "/")
+		if !ok || typ == "*" && subtype != "*" {
+			continue
+		}
+
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{typ, subtype, q})
+	}
+	return ranges
+}
@@ -0,0 +1,182 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type pet struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func assertContentType(t testing.TB, w *httptest.ResponseRecorder, want string) {
+	t.Helper()
+
+	if got := w.Header().Get("Content-Type"); got != want {
+		t.Errorf("got Content-Type %q, but want %q", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+
+	t.Run("JSON", func(t *testing.T) {
+		response := httptest.NewRecorder()
+
+		assertNoError(t, JSON(response, http.StatusCreated, pet{"rex"}))
+
+		assertStatus(t, response, http.StatusCreated)
+		assertContentType(t, response, "application/json; charset=utf-8")
+		assertBody(t, response, "{\"name\":\"rex\"}\n")
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		response := httptest.NewRecorder()
+
+		assertNoError(t, XML(response, http.StatusOK, pet{"rex"}))
+
+		assertStatus(t, response, http.StatusOK)
+		assertContentType(t, response, "application/xml; charset=utf-8")
+		assertBody(t, response, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<pet><name>rex</name></pet>")
+	})
+
+	t.Run("Text and HTML", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		assertNoError(t, Text(response, http.StatusOK, "hello"))
+		assertContentType(t, response, "text/plain; charset=utf-8")
+		assertBody(t, response, "hello")
+
+		response = httptest.NewRecorder()
+		assertNoError(t, HTML(response, http.StatusOK, "<p>hello</p>"))
+		assertContentType(t, response, "text/html; charset=utf-8")
+		assertBody(t, response, "<p>hello</p>")
+	})
+
+	t.Run("Blob", func(t *testing.T) {
+		response := httptest.NewRecorder()
+
+		assertNoError(t, Blob(response, http.StatusOK, "image/png", []byte(pngHeader)))
+
+		assertContentType(t, response, "image/png")
+		assertBody(t, response, pngHeader)
+		if got := response.Header().Get("Content-Length"); got != "8" {
+			t.Errorf(`got Content-Length %q, but want "8"`, got)
+		}
+	})
+
+	t.Run("NoContent", func(t *testing.T) {
+		response := httptest.NewRecorder()
+
+		NoContent(response)
+
+		assertStatus(t, response, http.StatusNoContent)
+		assertBody(t, response, "")
+	})
+
+	t.Run("writes nothing when encoding fails", func(t *testing.T) {
+		response := httptest.NewRecorder()
+
+		err := JSON(response, http.StatusOK, make(chan int))
+
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if response.Code != http.StatusOK || response.Body.Len() != 0 || response.Header().Get("Content-Type") != "" {
+			t.Error("expected nothing written")
+		}
+	})
+}
+
+func TestNegotiate(t *testing.T) {
+
+	cases := []struct {
+		accept string
+		want   string
+		body   string
+	}{
+		{"", "application/json; charset=utf-8", "{\"name\":\"rex\"}\n"},
+		{"*/*", "application/json; charset=utf-8", "{\"name\":\"rex\"}\n"},
+		{"application/xml", "application/xml; charset=utf-8", xmlPet},
+		{"text/*;q=0.5, application/json;q=0.4", "text/xml; charset=utf-8", xmlPet},
+		{"application/json;q=0.2, text/plain", "text/plain; charset=utf-8", "{rex}"},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("Accept %q", c.accept), func(t *testing.T) {
+			request := newRequest(http.MethodGet, newDummyURI("/pets/1"), "")
+			request.Header.Set("Accept", c.accept)
+			response := httptest.NewRecorder()
+
+			assertNoError(t, Negotiate(response, request, http.StatusOK, pet{"rex"}))
+
+			assertStatus(t, response, http.StatusOK)
+			assertContentType(t, response, c.want)
+			assertBody(t, response, c.body)
+			if got := response.Header().Get("Vary"); got != "Accept" {
+				t.Errorf(`got Vary %q, but want "Accept"`, got)
+			}
+		})
+	}
+
+	t.Run("replies 406 when nothing fits", func(t *testing.T) {
+		request := newRequest(http.MethodGet, newDummyURI("/pets/1"), "")
+		request.Header.Set("Accept", "image/png, application/json;q=0")
+		response := httptest.NewRecorder()
+
+		err := Negotiate(response, request, http.StatusOK, pet{"rex"})
+
+		var notAcceptable *NotAcceptableError
+		if !errors.As(err, &notAcceptable) {
+			t.Errorf("got %v, but want *NotAcceptableError", err)
+		}
+		assertStatus(t, response, http.StatusNotAcceptable)
+	})
+
+	t.Run("uses registered encoders", func(t *testing.T) {
+		RegisterEncoder("application/x-pet", EncoderFunc(func(w io.Writer, v any) error {
+			_, err := fmt.Fprintf(w, "pet:%s", v.(pet).Name)
+			return err
+		}))
+		defer func() {
+			encodersMu.Lock()
+			delete(encoders, "application/x-pet")
+			encoderTypes = encoderTypes[:len(encoderTypes)-1]
+			encodersMu.Unlock()
+		}()
+
+		request := newRequest(http.MethodGet, newDummyURI("/pets/1"), "")
+		request.Header.Set("Accept", "application/x-pet")
+		response := httptest.NewRecorder()
+
+		assertNoError(t, Negotiate(response, request, http.StatusOK, pet{"rex"}))
+
+		assertContentType(t, response, "application/x-pet")
+		assertBody(t, response, "pet:rex")
+	})
+}
+
+const xmlPet = "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<pet><name>rex</name></pet>"
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "text/html", "text/plain"}
+
+	cases := map[string]string{
+		"":                                  "application/json",
+		"text/html":                         "text/html",
+		"text/*":                            "text/html",
+		"text/*, text/html;q=0.1":           "text/plain",
+		"*/*;q=0.1, text/plain":             "text/plain",
+		"application/json;q=0, */*":         "text/html",
+		"image/png":                         "",
+		"text/plain;q=bad, application/xml": "",
+	}
+
+	for accept, want := range cases {
+		if got := NegotiateContentType(accept, offers); got != want {
+			t.Errorf("got %q for %q, but want %q", got, accept, want)
+		}
+	}
+}
@@ -1,68 +1,94 @@
 package router
 
 import (
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"reflect"
-	"strconv"
+	"strings"
 )
 
-type Params map[string]string
-
 // Request has a embedded http.Request
 // in addition to its extra methods
+//
+// The Requests given by the Router to handlers are reused once the
+// handler returns, so they must not be retained after that, like by
+// goroutines outliving the handler.
 type Request struct {
-	params Params
+	params     Params
+	pathParams pathParams
+	route      *Route
+	body       *bodyBuffer
+	version    string
+	problems   bool
 	*http.Request
 }
 
 // Get a map that holds every recognized param from the request path
 func (r *Request) Params() Params {
 	if r.params == nil {
-		r.params = make(Params)
+		r.params = r.pathParams.toParams()
 	}
 	return r.params
 }
 
+// Get the value of the param recognized from the request path, and
+// whether it exists, without making the map given by Params
+func (r *Request) Param(name string) (string, bool) {
+	if r.params != nil {
+		v, ok := r.params[name]
+		return v, ok
+	}
+	return r.pathParams.get(name)
+}
+
+// Get the route that matched the request, which is nil when
+// the request didn't match any route
+func (r *Request) Route() *Route {
+	return r.route
+}
+
 var (
 	ErrMissingPointer   = errors.New("router: a pointer must be given to parse request body into")
 	ErrUnsupportedInt   = errors.New("router: cannot parse request body into int")
+	ErrUnsupportedUint  = errors.New("router: cannot parse request body into uint")
 	ErrUnsupportedFloat = errors.New("router: cannot parse request body into float")
+	ErrUnsupportedBool  = errors.New("router: cannot parse request body into bool")
 	ErrNilPointer       = errors.New("router: a initialized pointer must be given to parse request body into")
 	ErrNilBody          = errors.New("router: nothing to read")
 )
 
 // Try to parse request body into the v, which
-// must be initialized. Actually v can be a pointer
-// to int (int64), float (float64), string and struct.
+// must be initialized.
+//
+// Scalars, that is strings, bools, integers and floats
+// of any size, are parsed from the text body, as well as
+// types implementing encoding.TextUnmarshaler. A []byte
+// gets the raw body.
+//
+// Structs, slices, arrays, maps and interfaces, as well as
+// types implementing json.Unmarshaler, are decoded by the
+// decoder registered for the request Content-Type, which
+// defaults to JSON. JSON, XML and forms are supported out of
+// the box, other types fail with *UnsupportedMediaTypeError.
+// The decoded value is then checked by Validate.
+//
+// Pointers are followed, being allocated when nil.
 // Different kinds are not supported and will cause error.
 //
-// Only JSON schematized request body can be parsed
-// into a struct.
+// Failures on reading the body are returned too, bodies
+// bigger than the router allows cause *BodyTooLargeError.
 func (r *Request) ParseBodyInto(v any) error {
 
 	if r.Body == nil {
 		return ErrNilBody
 	}
 
-	value := getPtrValue(v)
-
-	switch value.Kind() {
-	case reflect.String:
-		value.SetString(readBody(r))
-	case reflect.Int:
-		return r.bodyIntoInt(value)
-	case reflect.Float64:
-		return r.bodyIntoFloat(value)
-	case reflect.Struct:
-		return r.bodyIntoStruct(v)
-	default:
-		return fmt.Errorf("router: %T is not supported", v)
-	}
-	return nil
+	return r.bodyInto(getPtrValue(v))
 }
 
 func getPtrValue(v any) reflect.Value {
@@ -79,36 +105,133 @@ func getPtrValue(v any) reflect.Value {
 	return value.Elem()
 }
 
-func readBody(r *Request) string {
+// BodyTooLargeError records a request body bigger than the allowed size.
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("router: request body is bigger than %d bytes", e.Limit)
+}
+
+// StatusCode returns HTTP 413.
+func (e *BodyTooLargeError) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// Returns the HTTP status code carried by err, or 500 when
+// it carries none.
+func errorStatus(err error) int {
+	var sc interface{ StatusCode() int }
+	if errors.As(err, &sc) {
+		return sc.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+// Translates a failure of reading the body limited by the router.
+func bodyError(err error) error {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return &BodyTooLargeError{Limit: mbe.Limit}
+	}
+	return err
+}
+
+func readBody(r *Request) ([]byte, error) {
+	r.rewindBody()
 	raw, err := io.ReadAll(r.Body)
 	if err != nil {
-		return ""
+		return nil, fmt.Errorf("router: cannot read request body, %w", bodyError(err))
 	}
-	return string(raw)
+	return raw, nil
 }
 
-func (r *Request) bodyIntoInt(v reflect.Value) error {
-	value, err := strconv.Atoi(readBody(r))
-	if err != nil {
-		return ErrUnsupportedInt
+func (r *Request) bodyInto(value reflect.Value) error {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return r.bodyInto(value.Elem())
+	}
+
+	ptr := value.Addr().Interface()
+
+	_, isJSON := ptr.(json.Unmarshaler)
+	if tu, ok := ptr.(encoding.TextUnmarshaler); ok && !(isJSON && r.hasJSONBody()) {
+		raw, err := readBody(r)
+		if err != nil {
+			return err
+		}
+		return tu.UnmarshalText(raw)
+	}
+	if isJSON {
+		return r.decodeBody(ptr)
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		raw, err := readBody(r)
+		if err != nil {
+			return err
+		}
+		value.SetString(string(raw))
+	case reflect.Bool:
+		return r.bodyIntoScalar(value, ErrUnsupportedBool)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return r.bodyIntoScalar(value, ErrUnsupportedInt)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return r.bodyIntoScalar(value, ErrUnsupportedUint)
+	case reflect.Float32, reflect.Float64:
+		return r.bodyIntoScalar(value, ErrUnsupportedFloat)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			raw, err := readBody(r)
+			if err != nil {
+				return err
+			}
+			value.SetBytes(raw)
+			return nil
+		}
+		return r.decodeBody(ptr)
+	case reflect.Struct, reflect.Array, reflect.Map, reflect.Interface:
+		return r.decodeBody(ptr)
+	default:
+		return fmt.Errorf("router: %T is not supported", ptr)
 	}
-	v.SetInt(int64(value))
 	return nil
 }
 
-func (r *Request) bodyIntoFloat(v reflect.Value) error {
-	value, err := strconv.ParseFloat(readBody(r), 64)
+// Reports whether the body is JSON, by its Content-Type
+// or the lack of it.
+func (r *Request) hasJSONBody() bool {
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		return true
+	}
+	mt, _, _ := mime.ParseMediaType(ct)
+	return isJSONMediaType(mt)
+}
+
+func (r *Request) bodyIntoScalar(v reflect.Value, fail error) error {
+	raw, err := readBody(r)
 	if err != nil {
-		return ErrUnsupportedFloat
+		return err
+	}
+	if err := setValue(v, strings.TrimSpace(string(raw)), ""); err != nil {
+		return fail
 	}
-	v.SetFloat(value)
 	return nil
 }
 
-func (r *Request) bodyIntoStruct(v any) error {
-	err := json.NewDecoder(r.Body).Decode(v)
+func (r *Request) decodeBody(v any) error {
+	d, err := decoderFor(r.Header.Get("Content-Type"))
 	if err != nil {
-		return fmt.Errorf("router: cannot parse request body into %T", v)
+		return err
 	}
-	return nil
+	r.rewindBody()
+	if err := d.Decode(r.Request, v); err != nil {
+		return bodyError(err)
+	}
+	return Validate(v)
 }
@@ -1,10 +1,13 @@
 package router
 
 import (
+	"errors"
 	"net/http"
 	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 func TestRequest(t *testing.T) {
@@ -16,8 +19,8 @@ func TestRequest(t *testing.T) {
 		}
 
 		request := &Request{
-			params,
-			req,
+			params:  params,
+			Request: req,
 		}
 
 		if !reflect.DeepEqual(request.URL, req.URL) {
@@ -175,6 +178,179 @@ func TestParseBodyInto(t *testing.T) {
 		}
 	})
 
+	t.Run("parses body into every scalar kind", func(t *testing.T) {
+		var (
+			i64 int64
+			i8  int8
+			u   uint
+			u32 uint32
+			f32 float32
+			b   bool
+		)
+
+		cases := []struct {
+			body string
+			ptr  any
+			want any
+		}{
+			{"-9000000000", &i64, int64(-9000000000)},
+			{"-8", &i8, int8(-8)},
+			{"7\n", &u, uint(7)},
+			{"42", &u32, uint32(42)},
+			{"1.5", &f32, float32(1.5)},
+			{"true", &b, true},
+		}
+
+		for _, c := range cases {
+			request := newRequest(http.MethodPost, newDummyURI("/values"), c.body)
+
+			err := request.ParseBodyInto(c.ptr)
+
+			assertNoError(t, err)
+
+			got := reflect.ValueOf(c.ptr).Elem().Interface()
+			if got != c.want {
+				t.Errorf("got %v, but want %v", got, c.want)
+			}
+		}
+	})
+
+	t.Run("returns error for incompatible scalars", func(t *testing.T) {
+		var (
+			i8 int8
+			u  uint
+			b  bool
+			f  float32
+		)
+
+		cases := []struct {
+			body string
+			ptr  any
+			err  error
+		}{
+			{"300", &i8, ErrUnsupportedInt},
+			{"-1", &u, ErrUnsupportedUint},
+			{"yes", &b, ErrUnsupportedBool},
+			{"a", &f, ErrUnsupportedFloat},
+		}
+
+		for _, c := range cases {
+			request := newRequest(http.MethodPost, newDummyURI("/values"), c.body)
+
+			err := request.ParseBodyInto(c.ptr)
+			if err != c.err {
+				t.Errorf("got error %v, but want %v", err, c.err)
+			}
+		}
+	})
+
+	t.Run("parses body into bytes", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/raw"), "raw data")
+
+		var got []byte
+		err := request.ParseBodyInto(&got)
+
+		assertNoError(t, err)
+
+		if string(got) != "raw data" {
+			t.Errorf(`got %q, but want "raw data"`, got)
+		}
+	})
+
+	t.Run("parses json body into composite kinds", func(t *testing.T) {
+		var (
+			list  []int
+			array [2]string
+			dict  map[string]float64
+			value any
+		)
+
+		cases := []struct {
+			body string
+			ptr  any
+			want any
+		}{
+			{`[1, 2, 3]`, &list, []int{1, 2, 3}},
+			{`["a", "b"]`, &array, [2]string{"a", "b"}},
+			{`{"pi": 3.14}`, &dict, map[string]float64{"pi": 3.14}},
+			{`{"ok": true}`, &value, map[string]any{"ok": true}},
+		}
+
+		for _, c := range cases {
+			request := newRequest(http.MethodPost, newDummyURI("/values"), c.body)
+
+			err := request.ParseBodyInto(c.ptr)
+
+			assertNoError(t, err)
+
+			got := reflect.ValueOf(c.ptr).Elem().Interface()
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, but want %v", got, c.want)
+			}
+		}
+	})
+
+	t.Run("parses body into pointers", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/values"), "12")
+
+		var got *int
+		err := request.ParseBodyInto(&got)
+
+		assertNoError(t, err)
+
+		if got == nil || *got != 12 {
+			t.Errorf("got %v, but want pointer to 12", got)
+		}
+	})
+
+	t.Run("parses body into unmarshalers", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/values"), "d033fdc6-dbd2-427c-b18c-a41aa6449d75")
+		request.Header.Set("Content-Type", "text/plain")
+
+		var id UUID
+		err := request.ParseBodyInto(&id)
+
+		assertNoError(t, err)
+
+		if id.String() != "d033fdc6-dbd2-427c-b18c-a41aa6449d75" {
+			t.Errorf("got %s, but want d033fdc6-dbd2-427c-b18c-a41aa6449d75", id)
+		}
+
+		request = newRequest(http.MethodPost, newDummyURI("/values"), `"2024-03-01T10:00:00Z"`)
+		request.Header.Set("Content-Type", "application/json")
+
+		var when time.Time
+		err = request.ParseBodyInto(&when)
+
+		assertNoError(t, err)
+
+		if !when.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+			t.Errorf("got %v, but want 2024-03-01T10:00:00Z", when)
+		}
+	})
+
+	t.Run("returns error for unsupported kinds", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/values"), "1")
+
+		var got complex128
+		if err := request.ParseBodyInto(&got); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("returns read errors", func(t *testing.T) {
+		readErr := errors.New("connection reset")
+		req, _ := http.NewRequest(http.MethodPost, newDummyURI("/words"), iotest.ErrReader(readErr))
+		request := &Request{Request: req}
+
+		var got string
+		err := request.ParseBodyInto(&got)
+
+		if !errors.Is(err, readErr) {
+			t.Errorf("got error %v, but want %v", err, readErr)
+		}
+	})
+
 	t.Run("cause nothing to read error", func(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPost, newDummyURI("/add"), nil)
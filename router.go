@@ -6,8 +6,11 @@ import (
 	"net/url"
 	"path"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -33,15 +36,116 @@ func (f RouteHandlerFunc) ServeHTTP(w ResponseWriter, r *Request) {
 
 type routerEntry struct {
 	pattern string
+	pat     *compiledPattern
 	re      *regexp.Regexp
 	mh      map[string]RouteHandler
+	mr      map[string]*Route
+	mc      map[string][]*Route // routes with matchers
 }
 
+// Route describes a handler registered into the Router, it holds the
+// pattern and method which it was registered for and its optional
+// metadata.
+type Route struct {
+	Pattern string
+	Method  string
+	Name    string
+	Hidden  bool
+	Doc     RouteDoc
+	// Overrides the router MaxBodySize when not zero,
+	// a negative value means no limit.
+	MaxBodySize int64
+	// Overrides the router BodyBufferSize when not zero,
+	// a negative value disables the buffering.
+	BodyBufferSize int64
+	// Holds the API version served by the route, see Version.
+	Version string
+	// Hold the media types of the request and reply bodies,
+	// see Consumes and Produces.
+	Consumes []string
+	Produces []string
+	// When not zero, replies announce the route is deprecated,
+	// see Deprecated and Sunset.
+	Deprecated time.Time
+	Sunset     time.Time
+	// Overrides the router Timeout when not zero,
+	// a negative value means no limit.
+	Timeout  time.Duration
+	handler  RouteHandler
+	matchers []MatcherFunc
+	pat      *compiledPattern
+}
+
+// Handler returns the handler registered for the route.
+func (rt *Route) Handler() RouteHandler {
+	return rt.handler
+}
+
+// A RouteOption configures a route while it's being registered.
+type RouteOption func(*Route)
+
+// Name sets the route name, which identifies the route in
+// the router introspection.
+func Name(name string) RouteOption {
+	return func(rt *Route) {
+		rt.Name = name
+	}
+}
+
+// MaxBodySize limits the size, in bytes, of the route request
+// bodies, overriding the router limit. A negative n means no limit.
+func MaxBodySize(n int64) RouteOption {
+	return func(rt *Route) {
+		rt.MaxBodySize = n
+	}
+}
+
+// Hidden excludes the route from generated documentation.
+func Hidden() RouteOption {
+	return func(rt *Route) {
+		rt.Hidden = true
+	}
+}
+
+// Middleware wraps a RouteHandler into another one, that can act
+// before and after calling it.
+type Middleware func(RouteHandler) RouteHandler
+
 // Holds a simple request handler that replies HTTP 404 status
 var NotFoundHandler = RouteHandlerFunc(func(w ResponseWriter, r *Request) {
-	w.WriteHeader(http.StatusNotFound)
+	replyStatus(w, r, http.StatusNotFound)
 })
 
+// Replies HTTP 413 to requests declaring bodies bigger than the allowed.
+var bodyTooLargeHandler = RouteHandlerFunc(func(w ResponseWriter, r *Request) {
+	w.Header().Set("Connection", "close")
+	replyStatus(w, r, http.StatusRequestEntityTooLarge)
+})
+
+// Replies HTTP 400 to requests whose bodies cannot be read.
+var badRequestHandler = RouteHandlerFunc(func(w ResponseWriter, r *Request) {
+	replyStatus(w, r, http.StatusBadRequest)
+})
+
+// Replies the bare status code, like HTTP 415 to requests
+// whose bodies no route consumes.
+type statusHandler int
+
+func (sh statusHandler) ServeHTTP(w ResponseWriter, r *Request) {
+	replyStatus(w, r, int(sh))
+}
+
+// Replies HTTP 405 to requests whose method wasn't registered
+// for the matched pattern, listing the allowed ones.
+type methodNotAllowedHandler struct {
+	allow []string
+}
+
+func (mh *methodNotAllowedHandler) ServeHTTP(w ResponseWriter, r *Request) {
+	w.Header().Set("Allow", strings.Join(mh.allow, ", "))
+	replyStatus(w, r, http.StatusMethodNotAllowed)
+}
+
 type redirectHandler struct {
 	url  string
 	code int
@@ -93,11 +197,58 @@ func stripHostPort(host string) string {
 //
 // One parameterized pattern can be registered with a it's name
 // rounded by brackets, that is /customers/{id}.
+//
+// The pattern syntax of the standard ServeMux is also taken, so
+// patterns can start with a method, like "GET /customers/{id}",
+// end with {$}, and end with a wildcard matching the rest of the
+// path, like /files/{path...}. Params are also given through the
+// http.Request PathValue method.
 type Router struct {
+	// Holds the max size, in bytes, of request bodies. Bigger
+	// ones are replied with HTTP 413. Zero means no limit.
+	MaxBodySize int64
+	// When positive, request bodies are buffered before being
+	// dispatched, in memory up to this size in bytes and in
+	// temporary files beyond, so they can be read more than once.
+	// See Request.BufferBody.
+	BodyBufferSize int64
+	// When set, requests matching a pattern that wasn't registered
+	// for their method are replied with HTTP 405, and an Allow header,
+	// instead of HTTP 404.
+	HandleMethodNotAllowed bool
+	// When set, the replies of the router itself, like 404, 405 and
+	// 413, as well as the errors given to Error, are written as
+	// application/problem+json. See Problem.
+	Problems bool
+	// Finds the API version of requests, which selects the routes
+	// registered with Version. See VersionFromPath, VersionFromHeader
+	// and VersionFromAccept.
+	Versioning VersionFunc
+	// Holds the version of requests that carry none.
+	DefaultVersion string
+	// When set, the request context isn't made to carry the route and
+	// params, which saves the two allocations it costs on each request,
+	// and more for params. Serving static routes makes no allocations
+	// only then. They are still carried to the handlers adapted by
+	// FromHTTP and WrapMiddleware.
+	SkipContext bool
+	// Limits the time handlers take to serve a request when positive.
+	// The request context is then canceled and, if nothing was written
+	// yet, the request is replied with TimeoutStatus. Middlewares run
+	// out of the limit, so they see that reply. See Timeout.
+	Timeout time.Duration
+	// Holds the status replied to timed out requests, HTTP 503 when
+	// zero. HTTP 504 suits handlers waiting on upstream services.
+	TimeoutStatus int
+	// Holds the body replied to timed out requests, as plain text.
+	// When empty, the reply is the bare status, see Problems.
+	TimeoutBody string
+
 	mu   sync.RWMutex
 	m    map[string]*routerEntry // all patterns
 	sm   map[string]*routerEntry // slashed patterns
 	um   map[string]*routerEntry // unslashed patterns
+	mw   []Middleware
 	host bool
 }
 
@@ -106,6 +257,8 @@ func NewRouter() *Router {
 }
 
 // Dispatches the request to the handler whose pattern most closely matches the request URL.
+// The request context carries the matched route and params, see RouteFromContext and
+// ParamsFromContext.
 func (ro *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.RequestURI == "*" {
 		if r.ProtoAtLeast(1, 1) {
@@ -114,8 +267,104 @@ func (ro *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	h, _, params := ro.Handler(r)
-	h.ServeHTTP(w, &Request{params: params, Request: r})
+	req := requestPool.Get().(*Request)
+	defer releaseRequest(req)
+
+	h, rt, _, params, version := ro.lookup(r, req.pathParams[:0])
+	*req = Request{pathParams: params, route: rt, version: version, problems: ro.Problems, Request: r}
+	req.setPathValues()
+
+	writeDeprecation(w, rt)
+
+	if r.Body != nil {
+		h = ro.prepareBody(w, req, h)
+	}
+
+	// Made after the body is buffered, which the context carries too.
+	if !ro.SkipContext {
+		req.withContext()
+	}
+
+	if d := ro.timeout(rt); d > 0 {
+		h = &timeoutHandler{ro: ro, h: h, d: d}
+	}
+
+	ro.wrap(h).ServeHTTP(w, req)
+}
+
+// Holds the Requests made by ServeHTTP, to be reused.
+var requestPool = sync.Pool{
+	New: func() any {
+		return &Request{pathParams: make(pathParams, 0, 4)}
+	},
+}
+
+// releaseRequest frees the resources held by the request,
+// then puts it back into the pool.
+func releaseRequest(r *Request) {
+	r.closeBody()
+	*r = Request{pathParams: r.pathParams[:0]}
+	requestPool.Put(r)
+}
+
+// Applies the body size limit and buffering of the matched route,
+// returning the handler that must serve the request.
+func (ro *Router) prepareBody(w http.ResponseWriter, r *Request, h RouteHandler) RouteHandler {
+	if limit := ro.bodyLimit(r.route); limit > 0 {
+		if r.ContentLength > limit {
+			return bodyTooLargeHandler
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+
+	if size := ro.bodyBufferSize(r.route); size > 0 {
+		if err := r.BufferBody(size); err != nil {
+			if errorStatus(err) == http.StatusRequestEntityTooLarge {
+				return bodyTooLargeHandler
+			}
+			return badRequestHandler
+		}
+	}
+
+	return h
+}
+
+// Returns the body size limit of the route, which
+// defaults to the router one.
+func (ro *Router) bodyLimit(rt *Route) int64 {
+	if rt != nil && rt.MaxBodySize != 0 {
+		return rt.MaxBodySize
+	}
+	return ro.MaxBodySize
+}
+
+// Returns the body buffer size of the route, which
+// defaults to the router one.
+func (ro *Router) bodyBufferSize(rt *Route) int64 {
+	if rt != nil && rt.BodyBufferSize != 0 {
+		return rt.BodyBufferSize
+	}
+	return ro.BodyBufferSize
+}
+
+// Records middlewares that will wrap every handler dispatched by the
+// router, including the not found and redirect ones. The first given
+// middleware is the outermost.
+func (ro *Router) Wrap(mw ...Middleware) {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+
+	ro.mw = append(ro.mw, mw...)
+}
+
+func (ro *Router) wrap(h RouteHandler) RouteHandler {
+	ro.mu.RLock()
+	defer ro.mu.RUnlock()
+
+	for i := len(ro.mw) - 1; i >= 0; i-- {
+		h = ro.mw[i](h)
+	}
+	return h
 }
 
 // Returns the handler for the given request accordingly to the request characteristics
@@ -131,44 +380,77 @@ func (ro *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 //
 // To the unrecognizable request path it gives a not found handler, empty pattern and nil params.
 func (ro *Router) Handler(r *http.Request) (h RouteHandler, p string, params Params) {
+	h, rt, p, ps, _ := ro.lookup(r, nil)
+	if rt != nil {
+		params = ps.toParams()
+	}
+	return
+}
+
+// Like Handler, but also returns the matched route, which is nil
+// when the request is redirected or not found, and the request
+// API version. The params are appended to ps.
+func (ro *Router) lookup(r *http.Request, ps pathParams) (h RouteHandler, rt *Route, p string, params pathParams, version string) {
 
 	var host string
 	var path string
 
+	// The path to be routed, without the version prefix, if any.
+	rawPath := r.URL.Path
+	if ro.Versioning != nil {
+		version, rawPath = ro.Versioning(r)
+	}
+	if version == "" {
+		version = ro.DefaultVersion
+	}
+	var prefix string
+	if strings.HasSuffix(r.URL.Path, rawPath) {
+		prefix = r.URL.Path[:len(r.URL.Path)-len(rawPath)]
+	}
+
 	if r.Method == http.MethodConnect {
 		host = r.URL.Host
-		path = r.URL.Path
+		path = rawPath
 	} else {
 		host = stripHostPort(r.Host)
-		path = cleanPath(r.URL.Path)
+		path = cleanPath(rawPath)
 	}
 
-	p, h, params = ro.handler(host, path, r.Method)
+	p, h, rt, params = ro.handler(r, host, path, version, ps)
 
 	if h != nil {
 
-		if path != r.URL.Path {
-			u := &url.URL{Path: path, RawQuery: r.URL.RawQuery}
-			return RedirectHandler(u.String(), http.StatusMovedPermanently), u.Path, nil
+		if path != rawPath {
+			u := &url.URL{Path: prefix + path, RawQuery: r.URL.RawQuery}
+			return RedirectHandler(u.String(), http.StatusMovedPermanently), nil, u.Path, nil, version
 		}
 
 		return
 	}
 
 	if newPath, ok := ro.shouldRedirectToSlashPath(host, path); ok {
-		u := &url.URL{Path: newPath, RawQuery: r.URL.RawQuery}
-		return RedirectHandler(u.String(), http.StatusMovedPermanently), u.Path, nil
+		u := &url.URL{Path: prefix + newPath, RawQuery: r.URL.RawQuery}
+		return RedirectHandler(u.String(), http.StatusMovedPermanently), nil, u.Path, nil, version
 	}
 
 	if newPath, ok := ro.shouldRedirectToUnslashPath(host, path); ok {
-		u := &url.URL{Path: newPath, RawQuery: r.URL.RawQuery}
-		return RedirectHandler(u.String(), http.StatusMovedPermanently), u.Path, nil
+		u := &url.URL{Path: prefix + newPath, RawQuery: r.URL.RawQuery}
+		return RedirectHandler(u.String(), http.StatusMovedPermanently), nil, u.Path, nil, version
 	}
 
-	return NotFoundHandler, "", nil
+	if ro.HandleMethodNotAllowed {
+		if allow := ro.allowedMethods(r, host, path, version); len(allow) > 0 {
+			return &methodNotAllowedHandler{allow}, nil, "", nil, version
+		}
+	}
+
+	return NotFoundHandler, nil, "", nil, version
 }
 
-func (ro *Router) handler(host, path, method string) (p string, h RouteHandler, params Params) {
+// Returns the methods registered for the pattern matching the path.
+// Methods whose routes all have matchers or a version rejecting the
+// request are left out, since they wouldn't serve it either.
+func (ro *Router) allowedMethods(r *http.Request, host, path, version string) []string {
 	var e *routerEntry
 
 	if ro.host {
@@ -180,40 +462,142 @@ func (ro *Router) handler(host, path, method string) (p string, h RouteHandler,
 	}
 
 	if e == nil {
-		return "", nil, nil
+		return nil
 	}
 
-	h = e.mh[method]
+	ro.mu.RLock()
+	defer ro.mu.RUnlock()
 
-	if h == nil {
-		h = e.mh[MethodAll]
-		if h == nil {
-			return "", nil, nil
+	allow := make([]string, 0, len(e.mh)+len(e.mc)+1)
+	for m := range e.mh {
+		allow = append(allow, m)
+	}
+	for m, rts := range e.mc {
+		if _, ok := e.mh[m]; ok {
+			continue
+		}
+		if slices.ContainsFunc(rts, func(rt *Route) bool {
+			return (rt.Version == "" || rt.Version == version) && rt.matches(r)
+		}) {
+			allow = append(allow, m)
 		}
 	}
+	if slices.Contains(allow, http.MethodGet) && !slices.Contains(allow, http.MethodHead) {
+		allow = append(allow, http.MethodHead)
+	}
+	sort.Strings(allow)
+	return allow
+}
+
+func (ro *Router) handler(r *http.Request, host, path, version string, ps pathParams) (p string, h RouteHandler, rt *Route, params pathParams) {
+	var e *routerEntry
+
+	// The string matched by the entry, from which params are taken.
+	var target string
+
+	if ro.host {
+		target = host + path
+		e = ro.match(target)
+	}
 
-	matches := e.re.FindStringSubmatch(path)
-	params = make(Params)
+	if e == nil {
+		e, target = ro.match(path), path
+	}
+
+	if e == nil {
+		return "", nil, nil, nil
+	}
 
-	for i, tag := range e.re.SubexpNames() {
-		if i != 0 && tag != "" {
-			params[tag] = matches[i]
+	rt, status := e.route(r, r.Method, version)
+	if rt == nil && r.Method == http.MethodHead {
+		// Like ServeMux, GET routes serve HEAD requests too.
+		var getStatus int
+		rt, getStatus = e.route(r, http.MethodGet, version)
+		if status == 0 {
+			status = getStatus
+		}
+	}
+	if rt == nil {
+		var allStatus int
+		rt, allStatus = e.route(r, MethodAll, version)
+		if rt == nil {
+			if status == 0 {
+				status = allStatus
+			}
+			if status != 0 {
+				return "", statusHandler(status), nil, nil
+			}
+			return "", nil, nil, nil
 		}
 	}
-	return e.pattern, h, params
+	h = rt.handler
+
+	// Static patterns have no params to look for.
+	if e.pat.static() {
+		return e.pattern, h, rt, ps
+	}
+
+	// The groups of the expression are the params, in order.
+	matches := e.re.FindStringSubmatchIndex(target)
+	for i, name := range e.pat.params {
+		if a, b := matches[2*i+2], matches[2*i+3]; a >= 0 {
+			ps = append(ps, pathParam{name, target[a:b]})
+		}
+	}
+	return e.pattern, h, rt, ps
+}
+
+// Returns the route of the method that fits the request and version,
+// trying the routes with matchers, version or media types before the
+// one without them. When there's no route, it also returns HTTP 415
+// or 406 if some route was left out only by its media types.
+func (e *routerEntry) route(r *http.Request, method, version string) (*Route, int) {
+	var best *Route
+	var bestQ float64
+	var status int
+	for _, rt := range e.mc[method] {
+		if (rt.Version != "" && rt.Version != version) || !rt.matches(r) {
+			continue
+		}
+		if !rt.consumes(r) {
+			if status == 0 {
+				status = http.StatusUnsupportedMediaType
+			}
+			continue
+		}
+		q := rt.produces(r)
+		if q == 0 {
+			status = http.StatusNotAcceptable
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = rt, q
+			if q == 1 {
+				break
+			}
+		}
+	}
+	if best != nil {
+		return best, 0
+	}
+	if rt, ok := e.mr[method]; ok {
+		return rt, 0
+	}
+	return nil, status
 }
 
 func (ro *Router) shouldRedirectToUnslashPath(host, path string) (string, bool) {
 	ro.mu.RLock()
 	defer ro.mu.RUnlock()
 
-	if path[len(path)-1] != '/' {
+	if path[len(path)-1] != '/' || len(ro.um) == 0 {
 		return "", false
 	}
 
-	p := []string{path, host + path}
-
-	for _, c := range p {
+	for _, c := range ro.candidates(host, path) {
+		if c == "" {
+			continue
+		}
 		ps := c[:len(c)-1]
 		if _, ok := ro.um[ps]; ok {
 			return ps, true
@@ -234,13 +618,14 @@ func (ro *Router) shouldRedirectToSlashPath(host, path string) (string, bool) {
 	ro.mu.RLock()
 	defer ro.mu.RUnlock()
 
-	if path[len(path)-1] == '/' {
+	if path[len(path)-1] == '/' || len(ro.sm) == 0 {
 		return "", false
 	}
 
-	p := []string{path, host + path}
-
-	for _, c := range p {
+	for _, c := range ro.candidates(host, path) {
+		if c == "" {
+			continue
+		}
 		ps := c + "/"
 		if _, ok := ro.sm[ps]; ok {
 			return ps, true
@@ -256,6 +641,15 @@ func (ro *Router) shouldRedirectToSlashPath(host, path string) (string, bool) {
 	return "", false
 }
 
+// Returns the path and, when host patterns are registered, the host
+// and path, as the strings a pattern may match. Empty when unused.
+func (ro *Router) candidates(host, path string) [2]string {
+	if ro.host {
+		return [2]string{path, host + path}
+	}
+	return [2]string{path, ""}
+}
+
 func (ro *Router) match(path string) *routerEntry {
 	ro.mu.RLock()
 	defer ro.mu.RUnlock()
@@ -266,32 +660,25 @@ func (ro *Router) match(path string) *routerEntry {
 		return e
 	}
 
+	// Patterns matching the rest of the path are the last option, the
+	// most specific one, with the longest literal text, is taken.
+	var rest *routerEntry
 	for _, e := range ro.m {
 		if e.re.MatchString(path) {
-			return e
+			if !e.pat.isRest() {
+				return e
+			}
+			if rest == nil || e.pat.literals > rest.pat.literals ||
+				(e.pat.literals == rest.pat.literals && e.pattern < rest.pattern) {
+				rest = e
+			}
 		}
 	}
 
-	return nil
+	return rest
 }
 
-func createRegExp(pattern string) *regexp.Regexp {
-
-	builder := strings.Builder{}
-
-	builder.WriteRune('^')
-
-	paramsSeeker := regexp.MustCompile(`(\/\{[^\/]+\})`)
-	builder.WriteString(paramsSeeker.ReplaceAllStringFunc(pattern, func(m string) string {
-		return "/(?P<" + m[2:len(m)-1] + ">[^/]+)"
-	}))
-
-	builder.WriteString("$")
-
-	return regexp.MustCompile(strings.ReplaceAll(builder.String(), "/", `\/`))
-}
-
-func (ro *Router) register(pattern string, handler RouteHandler, method string) {
+func (ro *Router) register(pattern string, handler RouteHandler, method string, opts ...RouteOption) {
 	ro.mu.Lock()
 	defer ro.mu.Unlock()
 
@@ -303,24 +690,48 @@ func (ro *Router) register(pattern string, handler RouteHandler, method string)
 		panic("router: nil handler")
 	}
 
+	pattern, method, cp, err := parsePattern(pattern, method)
+	if err != nil {
+		panic(err)
+	}
+
 	if ro.m == nil {
 		ro.m = make(map[string]*routerEntry)
 	}
 
+	rt := &Route{
+		Pattern: pattern,
+		Method:  method,
+		handler: handler,
+	}
+	for _, opt := range opts {
+		opt(rt)
+	}
+
 	e, ok := ro.m[pattern]
 	if ok {
-		if _, ok := e.mh[method]; ok {
+		if _, ok := e.mh[method]; ok && !rt.conditional() {
 			panic("router: multiple registration into " + pattern)
 		}
 	} else {
 		e = &routerEntry{
 			pattern: pattern,
-			re:      createRegExp(pattern),
+			pat:     cp,
+			re:      regexp.MustCompile(cp.regexp()),
 			mh:      make(map[string]RouteHandler),
+			mr:      make(map[string]*Route),
+			mc:      make(map[string][]*Route),
 		}
 	}
 
-	e.mh[method] = handler
+	rt.pat = e.pat
+
+	if rt.conditional() {
+		e.mc[method] = append(e.mc[method], rt)
+	} else {
+		e.mh[method] = handler
+		e.mr[method] = rt
+	}
 
 	ro.m[pattern] = e
 
@@ -336,68 +747,101 @@ func (ro *Router) register(pattern string, handler RouteHandler, method string)
 		ro.um[e.pattern] = e
 	}
 
-	ro.host = pattern[0] != '/'
+	if pattern[0] != '/' {
+		ro.host = true
+	}
 }
 
-func (ro *Router) registerFunc(pattern string, handler func(w ResponseWriter, r *Request), method string) {
+func (ro *Router) registerFunc(pattern string, handler func(w ResponseWriter, r *Request), method string, opts ...RouteOption) {
 	if handler == nil {
 		panic("router: nil handler")
 	}
-	ro.register(pattern, RouteHandlerFunc(handler), method)
+	ro.register(pattern, RouteHandlerFunc(handler), method, opts...)
+}
+
+// Returns every registered route, ordered by pattern and method. Routes
+// sharing both are given in the order they are tried.
+func (ro *Router) Routes() []*Route {
+	ro.mu.RLock()
+	defer ro.mu.RUnlock()
+
+	routes := make([]*Route, 0, len(ro.m))
+	for _, e := range ro.m {
+		for m, rts := range e.mc {
+			routes = append(routes, rts...)
+			if rt, ok := e.mr[m]; ok {
+				routes = append(routes, rt)
+			}
+		}
+		for m, rt := range e.mr {
+			if _, ok := e.mc[m]; !ok {
+				routes = append(routes, rt)
+			}
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes
 }
 
 // Records the given pattern and handler to handle the corresponding path.
 // Use is a generic method correspondent
-func (ro *Router) Use(pattern string, handler RouteHandler) {
-	ro.register(pattern, handler, MethodAll)
+func (ro *Router) Use(pattern string, handler RouteHandler, opts ...RouteOption) {
+	ro.register(pattern, handler, MethodAll, opts...)
 }
 
 // Similar to Use method, but this method get a handler as a func.
 // And wrap it, to act like a RouteHandler.
-func (ro *Router) UseFunc(pattern string, handler func(w ResponseWriter, r *Request)) {
-	ro.registerFunc(pattern, RouteHandlerFunc(handler), MethodAll)
+func (ro *Router) UseFunc(pattern string, handler func(w ResponseWriter, r *Request), opts ...RouteOption) {
+	ro.registerFunc(pattern, RouteHandlerFunc(handler), MethodAll, opts...)
 }
 
 // Records the given pattern and handler to handle the corresponding path only on GET method.
-func (ro *Router) Get(pattern string, handler RouteHandler) {
-	ro.register(pattern, handler, MethodGet)
+func (ro *Router) Get(pattern string, handler RouteHandler, opts ...RouteOption) {
+	ro.register(pattern, handler, MethodGet, opts...)
 }
 
 // Similar to Get method, but this method get a handler as a func.
 // And wrap it, to act like a RouteHandler.
-func (ro *Router) GetFunc(pattern string, handler func(w ResponseWriter, r *Request)) {
-	ro.registerFunc(pattern, handler, MethodGet)
+func (ro *Router) GetFunc(pattern string, handler func(w ResponseWriter, r *Request), opts ...RouteOption) {
+	ro.registerFunc(pattern, handler, MethodGet, opts...)
 }
 
 // Records the given pattern and handler to handle the corresponding path only on POST method.
-func (ro *Router) Post(pattern string, handler RouteHandler) {
-	ro.register(pattern, handler, MethodPost)
+func (ro *Router) Post(pattern string, handler RouteHandler, opts ...RouteOption) {
+	ro.register(pattern, handler, MethodPost, opts...)
 }
 
 // Similar to Post method, but this method get a handler as a func.
 // And wrap it, to act like a RouteHandler.
-func (ro *Router) PostFunc(pattern string, handler func(w ResponseWriter, r *Request)) {
-	ro.registerFunc(pattern, handler, MethodPost)
+func (ro *Router) PostFunc(pattern string, handler func(w ResponseWriter, r *Request), opts ...RouteOption) {
+	ro.registerFunc(pattern, handler, MethodPost, opts...)
 }
 
 // Records the given pattern and handler to handle the corresponding path only on PUT method.
-func (ro *Router) Put(pattern string, handler RouteHandler) {
-	ro.register(pattern, handler, MethodPut)
+func (ro *Router) Put(pattern string, handler RouteHandler, opts ...RouteOption) {
+	ro.register(pattern, handler, MethodPut, opts...)
 }
 
 // Similar to Put method, but this method get a handler as a func.
 // And wrap it, to act like a RouteHandler.
-func (ro *Router) PutFunc(pattern string, handler func(w ResponseWriter, r *Request)) {
-	ro.registerFunc(pattern, handler, MethodPut)
+func (ro *Router) PutFunc(pattern string, handler func(w ResponseWriter, r *Request), opts ...RouteOption) {
+	ro.registerFunc(pattern, handler, MethodPut, opts...)
 }
 
 // Records the given pattern and handler to handle the corresponding path only on DELETE method.
-func (ro *Router) Delete(pattern string, handler RouteHandler) {
-	ro.register(pattern, handler, MethodDelete)
+func (ro *Router) Delete(pattern string, handler RouteHandler, opts ...RouteOption) {
+	ro.register(pattern, handler, MethodDelete, opts...)
 }
 
 // Similar to Delete method, but this method get a handler as a func.
 // And wrap it, to act like a RouteHandler.
-func (ro *Router) DeleteFunc(pattern string, handler func(w ResponseWriter, r *Request)) {
-	ro.registerFunc(pattern, handler, MethodDelete)
+func (ro *Router) DeleteFunc(pattern string, handler func(w ResponseWriter, r *Request), opts ...RouteOption) {
+	ro.registerFunc(pattern, handler, MethodDelete, opts...)
 }
@@ -1,12 +1,14 @@
 package router
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -524,6 +526,87 @@ func TestRouter(t *testing.T) {
 	})
 }
 
+func TestMaxBodySize(t *testing.T) {
+
+	var parseErr error
+	handler := func(w ResponseWriter, r *Request) {
+		var body string
+		parseErr = r.ParseBodyInto(&body)
+		if parseErr != nil {
+			w.WriteHeader(errorStatus(parseErr))
+		}
+	}
+
+	router := NewRouter()
+	router.MaxBodySize = 8
+	router.PostFunc("/small", handler)
+	router.PostFunc("/large", handler, MaxBodySize(16))
+	router.PostFunc("/unlimited", handler, MaxBodySize(-1))
+
+	cases := []struct {
+		path    string
+		body    string
+		chunked bool
+		status  int
+	}{
+		{"/small", "12345678", false, http.StatusOK},
+		{"/small", "123456789", false, http.StatusRequestEntityTooLarge},
+		{"/small", "123456789", true, http.StatusRequestEntityTooLarge},
+		{"/large", "123456789", false, http.StatusOK},
+		{"/large", "12345678901234567", true, http.StatusRequestEntityTooLarge},
+		{"/unlimited", "12345678901234567", false, http.StatusOK},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("returns %d for %d bytes on %q", c.status, len(c.body), c.path), func(t *testing.T) {
+			parseErr = nil
+			request, _ := http.NewRequest(http.MethodPost, newDummyURI(c.path), strings.NewReader(c.body))
+			if c.chunked {
+				request.ContentLength = -1
+			}
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assertStatus(t, response, c.status)
+
+			if c.chunked && c.status == http.StatusRequestEntityTooLarge {
+				var tooLarge *BodyTooLargeError
+				if !errors.As(parseErr, &tooLarge) {
+					t.Errorf("got error %v, but want *BodyTooLargeError", parseErr)
+				}
+			}
+		})
+	}
+
+	t.Run("returns 413 on binding forms", func(t *testing.T) {
+		var bindErr error
+		router.PostFunc("/form", func(w ResponseWriter, r *Request) {
+			var form struct {
+				Name string `form:"name"`
+			}
+			bindErr = r.Bind(&form)
+			if bindErr != nil {
+				w.WriteHeader(errorStatus(bindErr))
+			}
+		}, MaxBodySize(10))
+
+		body := "name=" + strings.Repeat("a", 100)
+		request, _ := http.NewRequest(http.MethodPost, newDummyURI("/form"), strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.ContentLength = -1
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusRequestEntityTooLarge)
+		var tooLarge *BodyTooLargeError
+		if !errors.As(bindErr, &tooLarge) {
+			t.Errorf("got error %v, but want *BodyTooLargeError", bindErr)
+		}
+	})
+}
+
 func BenchmarkRouterMath(b *testing.B) {
 	r := NewRouter()
 	r.Use("/", dummyHandler)
@@ -553,6 +636,112 @@ func BenchmarkRouterMath(b *testing.B) {
 	b.StopTimer()
 }
 
+func TestMethodNotAllowed(t *testing.T) {
+	router := NewRouter()
+	router.Get("/products/{id}", dummyHandler)
+	router.Delete("/products/{id}", dummyHandler)
+
+	t.Run("replies 404 by default", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, newDummyURI("/products/1"), nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusNotFound)
+	})
+
+	router.HandleMethodNotAllowed = true
+
+	t.Run("replies 405 with the allowed methods", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, newDummyURI("/products/1"), nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusMethodNotAllowed)
+		if got := response.Header().Get("Allow"); got != "DELETE, GET, HEAD" {
+			t.Errorf(`got Allow %q, but want "DELETE, GET, HEAD"`, got)
+		}
+	})
+
+	t.Run("replies 404 to unknown paths", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, newDummyURI("/users/1"), nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusNotFound)
+	})
+}
+
+// discardResponseWriter is a ResponseWriter that makes no allocations.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+func TestServeHTTPAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not exact under the race detector")
+	}
+	w := &discardResponseWriter{header: make(http.Header)}
+
+	// Carrying the context costs the copy of the *http.Request, made by
+	// WithContext, and the context itself.
+	cases := []struct {
+		url         string
+		skipContext bool
+		want        float64
+	}{
+		{"/products", true, 0},
+		{"/notfound", true, 0},
+		{"/products", false, 2},
+		{"/notfound", false, 2},
+	}
+
+	for _, c := range cases {
+		router := NewRouter()
+		router.SkipContext = c.skipContext
+		router.GetFunc("/products", dummyHandlerFunc)
+		router.GetFunc("/products/{id}", dummyHandlerFunc)
+
+		request := httptest.NewRequest(http.MethodGet, c.url, nil)
+
+		got := testing.AllocsPerRun(100, func() {
+			router.ServeHTTP(w, request)
+		})
+
+		if got != c.want {
+			t.Errorf("got %v allocations serving %s, skipping context %v, but want %v", got, c.url, c.skipContext, c.want)
+		}
+	}
+}
+
+func BenchmarkServeHTTP(b *testing.B) {
+	w := &discardResponseWriter{header: make(http.Header)}
+
+	for _, skip := range []bool{true, false} {
+		router := NewRouter()
+		router.SkipContext = skip
+		router.GetFunc("/products", dummyHandlerFunc)
+		router.GetFunc("/products/{id}", dummyHandlerFunc)
+
+		for _, url := range []string{"/products", "/products/3"} {
+			request := httptest.NewRequest(http.MethodGet, url, nil)
+
+			b.Run(fmt.Sprintf("%s skipping context %v", url, skip), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					router.ServeHTTP(w, request)
+				}
+			})
+		}
+	}
+}
+
 func assertRegistered(t testing.TB, router *Router, path string) {
 	t.Helper()
 
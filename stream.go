@@ -0,0 +1,185 @@
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// StreamOptions configures DecodeStream.
+type StreamOptions struct {
+	// Holds the max number of items of the body,
+	// zero means no limit.
+	MaxItems int
+}
+
+// ItemError records a failure on decoding or validating a single item
+// of a streamed body, which doesn't prevent the next items from being
+// decoded.
+type ItemError struct {
+	Index  int   // the item position, starting at zero
+	Offset int64 // the byte offset where the item ends
+	Err    error // the reason of the failure
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("router: item %d at offset %d, %v", e.Index, e.Offset, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns HTTP 400.
+func (e *ItemError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// TooManyItemsError records a streamed body with more items than allowed.
+type TooManyItemsError struct {
+	Limit int
+}
+
+func (e *TooManyItemsError) Error() string {
+	return fmt.Sprintf("router: request body has more than %d items", e.Limit)
+}
+
+// StatusCode returns HTTP 413.
+func (e *TooManyItemsError) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// Holds the media types of newline delimited JSON.
+var ndjsonMediaTypes = map[string]bool{
+	"application/x-ndjson":      true,
+	"application/jsonl":         true,
+	"application/jsonlines":     true,
+	"application/x-jsonlines":   true,
+	"application/json-seq":      true,
+	"application/x-json-stream": true,
+}
+
+// DecodeStream decodes the request body one item at a time, without
+// loading the whole body, calling fn with each one of them. The body
+// can be newline delimited JSON, when its Content-Type says so, like
+// application/x-ndjson, or otherwise a JSON array.
+//
+// Items that fail to decode, or to pass Validate, are given to fn along
+// with an *ItemError, and the stream goes on. Failures that prevent the
+// stream from going on, like malformed arrays, are returned, as well as
+// *TooManyItemsError when the body has more items than opts allow. It
+// stops as soon as fn returns an error, which is then returned.
+//
+// Items are decoded with the options of the registered JSONDecoder.
+func DecodeStream[T any](r *Request, opts StreamOptions, fn func(item T, err error) error) error {
+	if r.Body == nil {
+		return ErrNilBody
+	}
+	r.rewindBody()
+
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ndjsonMediaTypes[mt] {
+		return decodeLines(r.Body, opts, fn)
+	}
+	return decodeArray(r.Body, opts, fn)
+}
+
+func decodeLines[T any](body io.Reader, opts StreamOptions, fn func(T, error) error) error {
+	d := jsonDecoder()
+	br := bufio.NewReader(body)
+
+	var offset int64
+	for i := 0; ; {
+		line, err := br.ReadBytes('\n')
+		offset += int64(len(line))
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("router: cannot read request body, %w", bodyError(err))
+		}
+
+		// Record separators of json-seq are taken as white space.
+		if line = bytes.TrimSpace(bytes.Trim(line, "\x1e")); len(line) > 0 {
+			if opts.MaxItems > 0 && i >= opts.MaxItems {
+				return &TooManyItemsError{opts.MaxItems}
+			}
+
+			var item T
+			ierr := d.newDecoder(bytes.NewReader(line)).Decode(&item)
+			if ierr == nil {
+				ierr = Validate(&item)
+			}
+			if ferr := fn(item, itemError(i, offset, ierr)); ferr != nil {
+				return ferr
+			}
+			i++
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+func decodeArray[T any](body io.Reader, opts StreamOptions, fn func(T, error) error) error {
+	dec := jsonDecoder().newDecoder(body)
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	fail := func(err error) error {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return bodyError(err)
+		}
+		return &DecodeError{Type: reflect.SliceOf(typ), Offset: dec.InputOffset(), Err: err}
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return fail(err)
+	}
+	if tok != json.Delim('[') {
+		return fail(errors.New("body is not a JSON array"))
+	}
+
+	for i := 0; dec.More(); i++ {
+		if opts.MaxItems > 0 && i >= opts.MaxItems {
+			return &TooManyItemsError{opts.MaxItems}
+		}
+
+		var item T
+		ierr := dec.Decode(&item)
+		if ierr == nil {
+			ierr = Validate(&item)
+		} else if !isValueError(ierr) {
+			return fail(ierr)
+		}
+
+		if ferr := fn(item, itemError(i, dec.InputOffset(), ierr)); ferr != nil {
+			return ferr
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return fail(err)
+	}
+	return nil
+}
+
+// Reports whether the decoding failure is about the value itself,
+// in which case the decoder can go on with the next values.
+func isValueError(err error) bool {
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &typeErr) || strings.HasPrefix(err.Error(), "json: unknown field ")
+}
+
+func itemError(i int, offset int64, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ItemError{Index: i, Offset: offset, Err: err}
+}
@@ -0,0 +1,117 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type streamEvent struct {
+	ID   int    `json:"id"`
+	Kind string `json:"kind" validate:"required"`
+}
+
+func TestDecodeStream(t *testing.T) {
+
+	collect := func(request *Request, opts StreamOptions) ([]streamEvent, []int, error) {
+		var items []streamEvent
+		var failed []int
+		err := DecodeStream(request, opts, func(item streamEvent, err error) error {
+			var ie *ItemError
+			if errors.As(err, &ie) {
+				failed = append(failed, ie.Index)
+				return nil
+			}
+			items = append(items, item)
+			return nil
+		})
+		return items, failed, err
+	}
+
+	t.Run("decodes newline delimited JSON", func(t *testing.T) {
+		body := "{\"id\": 1, \"kind\": \"a\"}\n\n{\"id\": \"x\", \"kind\": \"b\"}\nnot json\n{\"id\": 4}\n{\"id\": 5, \"kind\": \"e\"}"
+		request := newRequest(http.MethodPost, newDummyURI("/events"), body)
+		request.Header.Set("Content-Type", "application/x-ndjson")
+
+		items, failed, err := collect(request, StreamOptions{})
+
+		assertNoError(t, err)
+
+		want := []streamEvent{{1, "a"}, {5, "e"}}
+		if !reflect.DeepEqual(items, want) {
+			t.Errorf("got items %v, but want %v", items, want)
+		}
+		if !reflect.DeepEqual(failed, []int{1, 2, 3}) {
+			t.Errorf("got failed items %v, but want [1 2 3]", failed)
+		}
+	})
+
+	t.Run("decodes JSON arrays", func(t *testing.T) {
+		body := `[{"id": 1, "kind": "a"}, {"id": "x", "kind": "b"}, {"id": 3, "kind": "c"}]`
+		request := newRequest(http.MethodPost, newDummyURI("/events"), body)
+
+		items, failed, err := collect(request, StreamOptions{})
+
+		assertNoError(t, err)
+
+		want := []streamEvent{{1, "a"}, {3, "c"}}
+		if !reflect.DeepEqual(items, want) {
+			t.Errorf("got items %v, but want %v", items, want)
+		}
+		if !reflect.DeepEqual(failed, []int{1}) {
+			t.Errorf("got failed items %v, but want [1]", failed)
+		}
+	})
+
+	t.Run("returns error for malformed arrays", func(t *testing.T) {
+		for _, body := range []string{`{"id": 1}`, `[{"id": 1}, {"id":`, `[{"id": 1} {"id": 2}]`} {
+			request := newRequest(http.MethodPost, newDummyURI("/events"), body)
+
+			_, _, err := collect(request, StreamOptions{})
+
+			var de *DecodeError
+			if !errors.As(err, &de) {
+				t.Errorf("got error %v for %q, but want *DecodeError", err, body)
+			}
+		}
+	})
+
+	t.Run("limits the number of items", func(t *testing.T) {
+		bodies := map[string]string{
+			"application/json":     `[{"id": 1, "kind": "a"}, {"id": 2, "kind": "b"}, {"id": 3, "kind": "c"}]`,
+			"application/x-ndjson": strings.Repeat("{\"id\": 1, \"kind\": \"a\"}\n", 3),
+		}
+
+		for ct, body := range bodies {
+			request := newRequest(http.MethodPost, newDummyURI("/events"), body)
+			request.Header.Set("Content-Type", ct)
+
+			items, _, err := collect(request, StreamOptions{MaxItems: 2})
+
+			var tooMany *TooManyItemsError
+			if !errors.As(err, &tooMany) {
+				t.Errorf("got error %v for %s, but want *TooManyItemsError", err, ct)
+			}
+			if len(items) != 2 {
+				t.Errorf("got %d items for %s, but want 2", len(items), ct)
+			}
+		}
+	})
+
+	t.Run("stops when callback fails", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/events"), `[{"id": 1, "kind": "a"}, {"id": 2, "kind": "b"}]`)
+		stop := errors.New("stop")
+
+		calls := 0
+		err := DecodeStream(request, StreamOptions{}, func(item streamEvent, err error) error {
+			calls++
+			return stop
+		})
+
+		if err != stop || calls != 1 {
+			t.Errorf("got error %v after %d calls, but want %v after 1", err, calls, stop)
+		}
+	})
+}
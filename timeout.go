@@ -0,0 +1,205 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Timeout limits the time the route handler takes to serve a request,
+// overriding the router Timeout. A negative d means no limit.
+func Timeout(d time.Duration) RouteOption {
+	return func(rt *Route) {
+		rt.Timeout = d
+	}
+}
+
+// Returns the timeout of the route, which
+// defaults to the router one.
+func (ro *Router) timeout(rt *Route) time.Duration {
+	if rt != nil && rt.Timeout != 0 {
+		return rt.Timeout
+	}
+	return ro.Timeout
+}
+
+// timeoutHandler serves requests by h, in its own goroutine, with a
+// context canceled after d. If h hasn't written when the time is up,
+// the request is replied with the router TimeoutStatus, and the writes
+// h makes from then on fail with http.ErrHandlerTimeout.
+//
+// It's the innermost handler, so middlewares see the timeout reply.
+type timeoutHandler struct {
+	ro *Router
+	h  RouteHandler
+	d  time.Duration
+}
+
+func (th *timeoutHandler) ServeHTTP(w ResponseWriter, r *Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), th.d)
+	defer cancel()
+
+	// h may outlive the request, which is reused once served,
+	// so it's given a copy of its own.
+	hr := new(Request)
+	*hr = *r
+	hr.pathParams = slices.Clone(r.pathParams)
+	hr.Request = r.Request.WithContext(ctx)
+
+	tw := &timeoutWriter{w: w, h: w.Header().Clone(), ro: th.ro, r: hr}
+	done := make(chan struct{})
+	panicked := make(chan any, 1)
+
+	go func() {
+		defer func() {
+			p := recover()
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.finished = true
+			if tw.abandoned {
+				hr.closeBody()
+			}
+			if p != nil {
+				panicked <- p
+				return
+			}
+			close(done)
+		}()
+		th.h.ServeHTTP(tw, hr)
+	}()
+
+	select {
+	case p := <-panicked:
+		panic(p)
+	case <-done:
+	case <-ctx.Done():
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		if tw.finished {
+			// h returned by the deadline, but got no chance to say so.
+			select {
+			case p := <-panicked:
+				panic(p)
+			default:
+				return
+			}
+		}
+		tw.expire()
+		// h still reads the body, so it's up to h to release it.
+		tw.abandoned = true
+		r.body = nil
+	}
+}
+
+// Writes the reply of timed out requests.
+func (ro *Router) replyTimeout(w http.ResponseWriter, r *Request) {
+	status := ro.TimeoutStatus
+	if status == 0 {
+		status = http.StatusServiceUnavailable
+	}
+	if ro.TimeoutBody == "" {
+		replyStatus(w, r, status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(ro.TimeoutBody))
+}
+
+// timeoutWriter passes the writes of a handler through, until the
+// request times out. The header is kept apart, so the handler may
+// still change it once the request is over.
+type timeoutWriter struct {
+	w         http.ResponseWriter
+	h         http.Header
+	ro        *Router
+	r         *Request
+	mu        sync.Mutex
+	wrote     bool
+	timedOut  bool
+	finished  bool // the handler returned
+	abandoned bool // the request was over before the handler returned
+}
+
+// expire ends the request once its context is done, replying it if
+// nothing was written and the time is up. It must be called with
+// tw.mu held.
+func (tw *timeoutWriter) expire() {
+	if tw.timedOut {
+		return
+	}
+	tw.timedOut = true
+	if !tw.wrote && errors.Is(tw.r.Context().Err(), context.DeadlineExceeded) {
+		tw.ro.replyTimeout(tw.w, tw.r)
+	}
+}
+
+// Reports whether the request is over, expiring it if its context is
+// done. It must be called with tw.mu held.
+func (tw *timeoutWriter) over() bool {
+	if !tw.timedOut && tw.r.Context().Err() != nil {
+		tw.expire()
+	}
+	return tw.timedOut
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.over() || tw.wrote {
+		return
+	}
+	tw.writeHeader(code)
+}
+
+func (tw *timeoutWriter) writeHeader(code int) {
+	dst := tw.w.Header()
+	for k := range dst {
+		if _, ok := tw.h[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range tw.h {
+		dst[k] = v
+	}
+	tw.w.WriteHeader(code)
+	tw.wrote = true
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.over() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wrote {
+		tw.writeHeader(http.StatusOK)
+	}
+	return tw.w.Write(b)
+}
+
+// Flush sends the written data to the client, if the underlying
+// ResponseWriter supports it, so streaming handlers keep working.
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.over() {
+		return
+	}
+	if !tw.wrote {
+		tw.writeHeader(http.StatusOK)
+	}
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,200 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+
+	// Waits for the request to be canceled, then tries to write.
+	lateHandler := func(errc chan<- error) RouteHandlerFunc {
+		return func(w ResponseWriter, r *Request) {
+			<-r.Context().Done()
+			if !errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+				errc <- r.Context().Err()
+				return
+			}
+			_, err := w.Write([]byte("late"))
+			errc <- err
+		}
+	}
+
+	t.Run("replies timed out requests", func(t *testing.T) {
+		errc := make(chan error, 1)
+
+		router := NewRouter()
+		router.Timeout = 10 * time.Millisecond
+		router.Get("/slow", lateHandler(errc))
+
+		request := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusServiceUnavailable)
+		if err := <-errc; !errors.Is(err, http.ErrHandlerTimeout) {
+			t.Errorf("got error %v on late write, but want %v", err, http.ErrHandlerTimeout)
+		}
+		assertBody(t, response, "")
+	})
+
+	t.Run("replies the configured status and body", func(t *testing.T) {
+		errc := make(chan error, 1)
+
+		router := NewRouter()
+		router.Timeout = 10 * time.Millisecond
+		router.TimeoutStatus = http.StatusGatewayTimeout
+		router.TimeoutBody = "upstream took too long"
+		router.Get("/slow", lateHandler(errc))
+
+		request := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		<-errc
+
+		assertStatus(t, response, http.StatusGatewayTimeout)
+		assertContentType(t, response, "text/plain; charset=utf-8")
+		assertBody(t, response, "upstream took too long")
+	})
+
+	t.Run("replies a problem", func(t *testing.T) {
+		errc := make(chan error, 1)
+
+		router := NewRouter()
+		router.Problems = true
+		router.Timeout = 10 * time.Millisecond
+		router.Get("/slow", lateHandler(errc))
+
+		request := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		<-errc
+
+		assertStatus(t, response, http.StatusServiceUnavailable)
+		assertContentType(t, response, "application/problem+json")
+	})
+
+	t.Run("takes the route timeout", func(t *testing.T) {
+		errc := make(chan error, 1)
+
+		router := NewRouter()
+		router.Timeout = time.Hour
+		router.Get("/slow", lateHandler(errc), Timeout(10*time.Millisecond))
+		router.GetFunc("/unlimited", func(w ResponseWriter, r *Request) {
+			if _, ok := r.Context().Deadline(); ok {
+				t.Error("expected no deadline")
+			}
+		}, Timeout(-1))
+
+		request := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		<-errc
+
+		assertStatus(t, response, http.StatusServiceUnavailable)
+
+		request = httptest.NewRequest(http.MethodGet, "/unlimited", nil)
+		response = httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusOK)
+	})
+
+	t.Run("keeps what was written in time", func(t *testing.T) {
+		errc := make(chan error, 1)
+
+		router := NewRouter()
+		router.Timeout = 10 * time.Millisecond
+		router.GetFunc("/slow", func(w ResponseWriter, r *Request) {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("started"))
+			w.(http.Flusher).Flush()
+			lateHandler(errc)(w, r)
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		<-errc
+
+		assertStatus(t, response, http.StatusAccepted)
+		assertBody(t, response, "started")
+		if !response.Flushed {
+			t.Error("expected the response flushed")
+		}
+	})
+
+	t.Run("leaves the buffered body to late handlers", func(t *testing.T) {
+		served := make(chan struct{})
+		type result struct {
+			body string
+			err  error
+		}
+		resc := make(chan result, 1)
+
+		router := NewRouter()
+		router.Timeout = 20 * time.Millisecond
+		router.BodyBufferSize = 4
+		router.PostFunc("/slow", func(w ResponseWriter, r *Request) {
+			<-served
+			var got string
+			err := r.ParseBodyInto(&got)
+			resc <- result{got, err}
+		})
+
+		request := httptest.NewRequest(http.MethodPost, "/slow", strings.NewReader("science"))
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		close(served)
+
+		assertStatus(t, response, http.StatusServiceUnavailable)
+		if res := <-resc; res.err != nil || res.body != "science" {
+			t.Errorf("got %q and error %v, but want %q", res.body, res.err, "science")
+		}
+	})
+
+	t.Run("serves fast handlers", func(t *testing.T) {
+		router := NewRouter()
+		router.Timeout = time.Minute
+		router.GetFunc("/users/{id}", func(w ResponseWriter, r *Request) {
+			if _, ok := r.Context().Deadline(); !ok {
+				t.Error("expected a deadline")
+			}
+			w.Header().Set("X-Id", r.PathValue("id"))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("done"))
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusCreated)
+		assertBody(t, response, "done")
+		if got := response.Header().Get("X-Id"); got != "7" {
+			t.Errorf("got header %q, but want %q", got, "7")
+		}
+	})
+
+	t.Run("passes panics on", func(t *testing.T) {
+		router := NewRouter()
+		router.Timeout = time.Minute
+		router.GetFunc("/panic", func(w ResponseWriter, r *Request) {
+			panic("boom")
+		})
+
+		defer func() {
+			if recover() != "boom" {
+				t.Error("expected the handler panic")
+			}
+		}()
+
+		request := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	})
+}
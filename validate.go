@@ -0,0 +1,383 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationFunc reports whether the value satisfies a validation rule,
+// param is the text after the equal sign of the rule, like 1 in min=1.
+type ValidationFunc func(v reflect.Value, param string) bool
+
+var (
+	validationsMu sync.RWMutex
+	validations   = map[string]ValidationFunc{
+		"required": validateRequired,
+		"min":      validateMin,
+		"max":      validateMax,
+		"len":      validateLen,
+		"email":    validateEmail,
+		"url":      validateURL,
+		"uuid":     validateUUID,
+		"oneof":    validateOneOf,
+	}
+)
+
+// RegisterValidation records a custom rule to be used in validate tags.
+// It replaces any rule with the same name.
+func RegisterValidation(name string, fn ValidationFunc) {
+	if name == "" || fn == nil {
+		panic("router: invalid validation")
+	}
+
+	validationsMu.Lock()
+	defer validationsMu.Unlock()
+
+	validations[name] = fn
+}
+
+// FieldError records a field that failed a validation rule.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationErrors holds every field that failed validation.
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "router: invalid " + strings.Join(msgs, "; ")
+}
+
+// StatusCode returns HTTP 422, since the request was understood
+// but its content is semantically wrong.
+func (e ValidationErrors) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// Validate checks the struct, or pointer to struct, v against the
+// rules given by its fields validate tags, like:
+//
+//	Name  string `validate:"required,max=100"`
+//	Email string `validate:"omitempty,email"`
+//	Kind  string `validate:"oneof=cat dog"`
+//
+// The builtin rules are required, min, max, len, email, url, uuid and
+// oneof, others can be added through RegisterValidation. The omitempty
+// rule skips the remaining ones when the field is empty. Nested structs,
+// and slices and maps of them, are validated too.
+//
+// Fields are reported by their JSON names, when tagged, and failures
+// are returned together as ValidationErrors. Values of other kinds
+// are always valid. Tags naming unknown rules, or min, max and len
+// rules without a number, make it fail with an error telling so.
+//
+// Validate is called by ParseBodyInto and Bind after filling structs.
+func Validate(v any) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	var errs ValidationErrors
+	if err := validateValue(value, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateValue(v reflect.Value, path string, errs *ValidationErrors) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() != timeType {
+			return validateStruct(v, path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateValue(v.Index(i), path+"["+strconv.Itoa(i)+"]", errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateValue(iter.Value(), path+"["+fmt.Sprint(iter.Key())+"]", errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateStruct(v reflect.Value, path string, errs *ValidationErrors) error {
+	t := v.Type()
+	if err := checkTags(t); err != nil {
+		return err
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			if err := validateValue(fv, path, errs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		field := fieldName(f)
+		if path != "" {
+			field = path + "." + field
+		}
+
+		if tag := f.Tag.Get("validate"); tag != "" && tag != "-" {
+			if !validateField(fv, field, tag, errs) {
+				continue
+			}
+		}
+
+		if err := validateValue(fv, field, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Holds the struct types whose validate tags were checked.
+var checkedTags sync.Map
+
+// checkTags makes sure the validate tags of the fields of t name known
+// rules, and that min, max and len rules are given numbers.
+func checkTags(t reflect.Type) error {
+	if _, ok := checkedTags.Load(t); ok {
+		return nil
+	}
+
+	validationsMu.RLock()
+	defer validationsMu.RUnlock()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("validate")
+		if !f.IsExported() || tag == "" || tag == "-" || f.Anonymous && f.Tag.Get("json") == "" {
+			continue
+		}
+
+		for _, rule := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(rule, "=")
+			if name == "omitempty" {
+				continue
+			}
+			if _, ok := validations[name]; !ok {
+				return fmt.Errorf("router: unknown validation %q on field %s of %v", name, f.Name, t)
+			}
+			switch name {
+			case "min", "max", "len":
+				if _, err := strconv.ParseFloat(param, 64); err != nil {
+					return fmt.Errorf("router: validation %q on field %s of %v must be given a number", rule, f.Name, t)
+				}
+			}
+		}
+	}
+
+	checkedTags.Store(t, true)
+	return nil
+}
+
+func fieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+// validateField applies the tag rules to the field and
+// reports whether it passed all of them.
+func validateField(v reflect.Value, field, tag string, errs *ValidationErrors) bool {
+	validationsMu.RLock()
+	defer validationsMu.RUnlock()
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		if name == "omitempty" {
+			if isEmpty(v) {
+				return true
+			}
+			continue
+		}
+
+		// Known to exist, see checkTags.
+		if !validations[name](v, param) {
+			*errs = append(*errs, &FieldError{
+				Field:   field,
+				Rule:    name,
+				Param:   param,
+				Message: validationMessage(v, name, param),
+			})
+			return false
+		}
+	}
+	return true
+}
+
+func validationMessage(v reflect.Value, rule, param string) string {
+	sized := ""
+	switch indirect(v).Kind() {
+	case reflect.String:
+		sized = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		sized = " items"
+	}
+
+	switch rule {
+	case "required":
+		return "is required"
+	case "min":
+		return "must have at least " + param + sized
+	case "max":
+		return "must have at most " + param + sized
+	case "len":
+		return "must have exactly " + param + sized
+	case "email", "url":
+		return "must be a valid " + rule
+	case "uuid":
+		return "must be a valid UUID"
+	case "oneof":
+		return "must be one of [" + param + "]"
+	}
+	return "failed on " + rule + " rule"
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func validateRequired(v reflect.Value, _ string) bool {
+	return !isEmpty(v)
+}
+
+// size returns the length of strings, slices and maps, or the value
+// of numbers, which are compared by min, max and len rules.
+func size(v reflect.Value) (float64, bool) {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func compareSize(v reflect.Value, param string, cmp func(a, b float64) bool) bool {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	n, ok := size(v)
+	return !ok || cmp(n, limit)
+}
+
+func validateMin(v reflect.Value, param string) bool {
+	return compareSize(v, param, func(a, b float64) bool { return a >= b })
+}
+
+func validateMax(v reflect.Value, param string) bool {
+	return compareSize(v, param, func(a, b float64) bool { return a <= b })
+}
+
+func validateLen(v reflect.Value, param string) bool {
+	return compareSize(v, param, func(a, b float64) bool { return a == b })
+}
+
+func validateEmail(v reflect.Value, _ string) bool {
+	v = indirect(v)
+	if v.Kind() != reflect.String {
+		return true
+	}
+	addr, err := mail.ParseAddress(v.String())
+	return err == nil && addr.Address == v.String()
+}
+
+func validateURL(v reflect.Value, _ string) bool {
+	v = indirect(v)
+	if v.Kind() != reflect.String {
+		return true
+	}
+	u, err := url.Parse(v.String())
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func validateUUID(v reflect.Value, _ string) bool {
+	v = indirect(v)
+	if v.Kind() != reflect.String {
+		return true
+	}
+	_, err := ParseUUID(v.String())
+	return err == nil
+}
+
+func validateOneOf(v reflect.Value, param string) bool {
+	v = indirect(v)
+	s := fmt.Sprint(v.Interface())
+	for _, opt := range strings.Fields(param) {
+		if s == opt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type validAddress struct {
+	Street string `json:"street" validate:"required"`
+	Zip    string `json:"zip" validate:"len=5"`
+}
+
+type validUser struct {
+	Name      string         `json:"name" validate:"required,max=10"`
+	Age       int            `json:"age" validate:"min=18,max=130"`
+	Email     string         `json:"email" validate:"omitempty,email"`
+	Role      string         `json:"role" validate:"oneof=admin user"`
+	Nick      *string        `json:"nick" validate:"omitempty,min=3"`
+	Tags      []string       `json:"tags" validate:"max=2"`
+	Addresses []validAddress `json:"addresses"`
+	Code      string         `validate:"even"`
+}
+
+func TestValidate(t *testing.T) {
+
+	RegisterValidation("even", func(v reflect.Value, _ string) bool {
+		return v.Len()%2 == 0
+	})
+
+	t.Run("accepts valid structs", func(t *testing.T) {
+		u := validUser{Name: "Alex", Age: 30, Role: "admin", Addresses: []validAddress{{"Main St", "12345"}}}
+		assertNoError(t, Validate(&u))
+	})
+
+	t.Run("reports every invalid field", func(t *testing.T) {
+		nick := "ab"
+		u := validUser{
+			Name:      "",
+			Age:       12,
+			Email:     "nope",
+			Role:      "root",
+			Nick:      &nick,
+			Tags:      []string{"a", "b", "c"},
+			Addresses: []validAddress{{"Main St", "12345"}, {"", "1"}},
+			Code:      "abc",
+		}
+
+		err := Validate(u)
+
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("got error %v, but want ValidationErrors", err)
+		}
+
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Field+" "+e.Rule)
+		}
+		want := []string{
+			"name required",
+			"age min",
+			"email email",
+			"role oneof",
+			"nick min",
+			"tags max",
+			"addresses[1].street required",
+			"addresses[1].zip len",
+			"Code even",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %q, but want %q", got, want)
+		}
+
+		if errs.StatusCode() != http.StatusUnprocessableEntity {
+			t.Errorf("got status %d, but want %d", errs.StatusCode(), http.StatusUnprocessableEntity)
+		}
+		if errs[1].Message != "must have at least 18" {
+			t.Errorf("got message %q", errs[1].Message)
+		}
+	})
+
+	t.Run("fails on mistaken tags", func(t *testing.T) {
+		cases := []struct {
+			v    any
+			want string
+		}{
+			{&struct {
+				Name string `validate:"requird"`
+			}{}, `unknown validation "requird" on field Name`},
+			{&struct {
+				Age int `validate:"min=ten"`
+			}{}, `validation "min=ten" on field Age`},
+			{&struct {
+				Items []struct {
+					Zip string `validate:"len="`
+				}
+			}{Items: make([]struct {
+				Zip string `validate:"len="`
+			}, 1)}, `validation "len=" on field Zip`},
+		}
+
+		for _, c := range cases {
+			err := Validate(c.v)
+
+			var errs ValidationErrors
+			if err == nil || errors.As(err, &errs) || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("got error %v, but want one telling %s", err, c.want)
+			}
+		}
+	})
+
+	t.Run("runs after parsing body", func(t *testing.T) {
+		request := newRequest(http.MethodPost, newDummyURI("/users"), `{"name": "Alex", "age": 3, "role": "user", "Code": "ab"}`)
+
+		var got validUser
+		err := request.ParseBodyInto(&got)
+
+		var errs ValidationErrors
+		if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "age" {
+			t.Errorf("got error %v, but want age to be invalid", err)
+		}
+	})
+
+	t.Run("runs after binding", func(t *testing.T) {
+		request := newRequest(http.MethodGet, newDummyURI("/users?page=0"), "")
+
+		var got struct {
+			Page int `query:"page" validate:"min=1"`
+		}
+		err := request.Bind(&got)
+
+		if err == nil || !strings.Contains(err.Error(), "Page must have at least 1") {
+			t.Errorf("got error %v, but want Page to be invalid", err)
+		}
+	})
+}
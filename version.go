@@ -0,0 +1,153 @@
+package router
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionFunc finds the API version requested, returning an empty
+// version when the request carries none. It also returns the path to
+// be routed, which is the request path, unless the version is part
+// of it.
+type VersionFunc func(r *http.Request) (version, path string)
+
+// VersionFromPath finds the version in the first path segment, made of
+// the given prefix followed by the version, like /v2/items, routing the
+// rest of the path, like /items. The version is made of digits, maybe
+// separated by dots, like 2 or 2.1, so /videos is not mistaken for
+// version "ideos".
+func VersionFromPath(prefix string) VersionFunc {
+	return func(r *http.Request) (string, string) {
+		p := r.URL.Path
+		if !strings.HasPrefix(p, "/") {
+			// OpenAPI asks for the prefix by a request without path.
+			if dst, ok := r.Context().Value(versionPrefixKey{}).(**string); ok {
+				*dst = &prefix
+			}
+			return "", p
+		}
+		seg, rest, _ := strings.Cut(p[1:], "/")
+		v, ok := strings.CutPrefix(seg, prefix)
+		if !ok || !isVersion(v) {
+			return "", p
+		}
+		return v, "/" + rest
+	}
+}
+
+// versionPrefixKey is the context key through which VersionFromPath
+// gives its prefix, see versionPrefix.
+type versionPrefixKey struct{}
+
+// Returns the prefix of the versions found by f, when it was made by
+// VersionFromPath, so the versions are documented in the paths.
+func versionPrefix(f VersionFunc) (string, bool) {
+	if f == nil {
+		return "", false
+	}
+	var prefix *string
+	ctx := context.WithValue(context.Background(), versionPrefixKey{}, &prefix)
+	r := &http.Request{Method: http.MethodGet, URL: &url.URL{}, Header: make(http.Header)}
+	f(r.WithContext(ctx))
+	if prefix == nil {
+		return "", false
+	}
+	return *prefix, true
+}
+
+// Reports whether v is made of digits, maybe separated by dots.
+func isVersion(v string) bool {
+	if v == "" {
+		return false
+	}
+	for _, part := range strings.Split(v, ".") {
+		if part == "" || strings.Trim(part, "0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// VersionFromHeader finds the version in the given request header,
+// like X-API-Version.
+func VersionFromHeader(name string) VersionFunc {
+	return func(r *http.Request) (string, string) {
+		return strings.TrimSpace(r.Header.Get(name)), r.URL.Path
+	}
+}
+
+// VersionFromAccept finds the version in the vendor media types of
+// the Accept header, either in the subtype, like application/vnd.x.v2+json,
+// or in the version parameter, like application/vnd.x+json; version=2,
+// where x is the given vendor.
+func VersionFromAccept(vendor string) VersionFunc {
+	tree := "vnd." + strings.ToLower(vendor)
+	return func(r *http.Request) (string, string) {
+		for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+			mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			_, subtype, _ := strings.Cut(mt, "/")
+			subtype, _, _ = strings.Cut(subtype, "+")
+
+			if v, ok := strings.CutPrefix(subtype, tree+".v"); ok && v != "" {
+				return v, r.URL.Path
+			}
+			if subtype == tree && params["version"] != "" {
+				return params["version"], r.URL.Path
+			}
+		}
+		return "", r.URL.Path
+	}
+}
+
+// Version makes the route serve only requests of the given API version,
+// as found by the router Versioning. Routes of different versions can
+// share their pattern and method, and requests of versions without a
+// route of their own fall back to the route without version, if any.
+func Version(v string) RouteOption {
+	return func(rt *Route) {
+		rt.Version = v
+	}
+}
+
+// Deprecated makes the route replies announce it's deprecated since
+// the given time, through the Deprecation header.
+func Deprecated(since time.Time) RouteOption {
+	return func(rt *Route) {
+		rt.Deprecated = since
+	}
+}
+
+// Sunset makes the route replies announce it will stop being served
+// at the given time, through the Sunset header.
+func Sunset(at time.Time) RouteOption {
+	return func(rt *Route) {
+		rt.Sunset = at
+	}
+}
+
+// Writes the Deprecation and Sunset headers of the route, if any.
+func writeDeprecation(w http.ResponseWriter, rt *Route) {
+	if rt == nil {
+		return
+	}
+	if !rt.Deprecated.IsZero() {
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(rt.Deprecated.Unix(), 10))
+	}
+	if !rt.Sunset.IsZero() {
+		w.Header().Set("Sunset", rt.Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// Get the API version of the request, as found by the router Versioning,
+// or the router DefaultVersion when the request carries none
+func (r *Request) Version() string {
+	return r.version
+}
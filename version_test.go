@@ -0,0 +1,140 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func versionHandler(name string) RouteHandlerFunc {
+	return func(w ResponseWriter, r *Request) {
+		w.Write([]byte(name + " " + r.Version()))
+	}
+}
+
+func newVersionedRouter(versioning VersionFunc) *Router {
+	router := NewRouter()
+	router.Versioning = versioning
+	router.DefaultVersion = "1"
+	router.Get("/items", versionHandler("items.v1"), Version("1"),
+		Deprecated(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Sunset(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)))
+	router.Get("/items", versionHandler("items.v2"), Version("2"))
+	router.Get("/items/{id}", versionHandler("item"))
+	router.Get("/orders/", versionHandler("orders.v2"), Version("2"))
+	router.Get("/videos/{id}", versionHandler("video"))
+	return router
+}
+
+func TestVersioning(t *testing.T) {
+
+	t.Run("from path", func(t *testing.T) {
+		router := newVersionedRouter(VersionFromPath("v"))
+
+		cases := []struct {
+			path   string
+			status int
+			body   string
+		}{
+			{"/v1/items", http.StatusOK, "items.v1 1"},
+			{"/v2/items", http.StatusOK, "items.v2 2"},
+			{"/items", http.StatusOK, "items.v1 1"},
+			{"/v3/items/7", http.StatusOK, "item 3"},
+			{"/v3/items", http.StatusNotFound, ""},
+			{"/v1/orders/", http.StatusNotFound, ""},
+			{"/v2.1/items/7", http.StatusOK, "item 2.1"},
+			{"/videos/1", http.StatusOK, "video 1"},
+			{"/v/items/7", http.StatusNotFound, ""},
+			{"/v2./items/7", http.StatusNotFound, ""},
+		}
+
+		for _, c := range cases {
+			request := httptest.NewRequest(http.MethodGet, c.path, nil)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assertStatus(t, response, c.status)
+			assertBody(t, response, c.body)
+		}
+	})
+
+	t.Run("redirects keeping the version prefix", func(t *testing.T) {
+		router := newVersionedRouter(VersionFromPath("v"))
+		request := httptest.NewRequest(http.MethodGet, "/v2/orders", nil)
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assertStatus(t, response, http.StatusMovedPermanently)
+		if got := response.Header().Get("Location"); got != "/v2/orders/" {
+			t.Errorf(`got Location %q, but want "/v2/orders/"`, got)
+		}
+	})
+
+	t.Run("from header", func(t *testing.T) {
+		router := newVersionedRouter(VersionFromHeader("X-API-Version"))
+		request := httptest.NewRequest(http.MethodGet, "/items", nil)
+		request.Header.Set("X-API-Version", "2")
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assertBody(t, response, "items.v2 2")
+	})
+
+	t.Run("from accept", func(t *testing.T) {
+		router := newVersionedRouter(VersionFromAccept("x"))
+
+		for accept, want := range map[string]string{
+			"application/vnd.x.v2+json":           "items.v2 2",
+			"application/vnd.x+json; version=2":   "items.v2 2",
+			"text/html, application/vnd.x.v1+xml": "items.v1 1",
+			"application/json":                    "items.v1 1",
+		} {
+			request := httptest.NewRequest(http.MethodGet, "/items", nil)
+			request.Header.Set("Accept", accept)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assertBody(t, response, want)
+		}
+	})
+
+	t.Run("announces deprecation", func(t *testing.T) {
+		router := newVersionedRouter(VersionFromPath("v"))
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		if got := response.Header().Get("Deprecation"); got != "@1767225600" {
+			t.Errorf(`got Deprecation %q, but want "@1767225600"`, got)
+		}
+		if got := response.Header().Get("Sunset"); got != "Fri, 01 Jan 2027 00:00:00 GMT" {
+			t.Errorf(`got Sunset %q, but want "Fri, 01 Jan 2027 00:00:00 GMT"`, got)
+		}
+
+		request = httptest.NewRequest(http.MethodGet, "/v2/items", nil)
+		response = httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		if got := response.Header().Get("Deprecation"); got != "" {
+			t.Errorf("got Deprecation %q, but want none", got)
+		}
+	})
+
+	t.Run("ignores versioned routes without versioning", func(t *testing.T) {
+		router := NewRouter()
+		router.Get("/items", versionHandler("items.v2"), Version("2"))
+		router.Get("/items", versionHandler("items"))
+
+		request := httptest.NewRequest(http.MethodGet, "/items", nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		assertBody(t, response, "items ")
+	})
+}